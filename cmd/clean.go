@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+
+	"goscaffold/pkg/backup"
+	"goscaffold/pkg/ui"
+)
+
+var (
+	cleanOlderThan string
+	cleanDryRun    bool
+	cleanYes       bool
+)
+
+var cleanCmd = &cobra.Command{
+	Use:   "clean [flags]",
+	Short: "Delete backups",
+	Long: `Delete the backup directory (backup.path, default .goscaffold-backup),
+including the sessions undo relies on and the merge bases. With --older-than
+only backups and bases older than the window are deleted, as the
+backup.retention pruning does.`,
+	Example: `  goscaffold clean --dry-run
+  goscaffold clean --older-than 7d
+  goscaffold clean -y`,
+	Args: cobra.NoArgs,
+	RunE: runClean,
+}
+
+func init() {
+	cleanCmd.Flags().StringVar(&cleanOlderThan, "older-than", "", "Only delete backups older than this (h, d or w suffix, e.g. 7d)")
+	cleanCmd.Flags().BoolVarP(&cleanDryRun, "dry-run", "d", false, "List what would be deleted")
+	cleanCmd.Flags().BoolVarP(&cleanYes, "yes", "y", false, "Delete without asking")
+
+	rootCmd.AddCommand(cleanCmd)
+}
+
+func runClean(cmd *cobra.Command, args []string) error {
+	bm, err := newBackupManager()
+	if err != nil {
+		return err
+	}
+
+	var window time.Duration
+	if cleanOlderThan != "" {
+		if window, err = backup.ParseRetention(cleanOlderThan); err != nil {
+			return fmt.Errorf("invalid --older-than: %w", err)
+		}
+		if window == 0 {
+			return fmt.Errorf("invalid --older-than %q (must be more than 0)", cleanOlderThan)
+		}
+	}
+
+	var entries []backup.BackupEntry
+	if window > 0 {
+		entries, err = bm.Expired(window)
+	} else {
+		entries, err = bm.List()
+	}
+	if err != nil {
+		return fmt.Errorf("list backups: %w", err)
+	}
+
+	if _, err := os.Stat(bm.Dir()); os.IsNotExist(err) {
+		log.Info("No backups found", "dir", bm.Dir())
+		return nil
+	}
+	if window > 0 && len(entries) == 0 {
+		log.Info("No backups older than "+cleanOlderThan, "dir", bm.Dir())
+		return nil
+	}
+
+	if cleanDryRun {
+		for _, e := range entries {
+			fmt.Printf("%s\t%s\t%s\n", e.Path, e.Original, e.Time.Format("2006-01-02 15:04:05"))
+		}
+		if window == 0 {
+			fmt.Printf("%s\t(directory, with import sessions)\n", bm.Dir())
+		}
+		return nil
+	}
+
+	if !cleanYes {
+		question := fmt.Sprintf("Delete %s and its %d backup(s)?", bm.Dir(), len(entries))
+		if window > 0 {
+			question = fmt.Sprintf("Delete %d backup(s) older than %s from %s?", len(entries), cleanOlderThan, bm.Dir())
+		}
+		ok, err := ui.Confirm(bufio.NewReader(os.Stdin), os.Stdout, question)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			log.Info("Nothing deleted")
+			return nil
+		}
+	}
+
+	if window == 0 {
+		if err := bm.RemoveAll(); err != nil {
+			return err
+		}
+		log.Info("Deleted backups", "dir", bm.Dir(), "count", len(entries))
+		return nil
+	}
+
+	for _, e := range entries {
+		if err := bm.Remove(e); err != nil {
+			return err
+		}
+	}
+	log.Info("Deleted backups", "dir", bm.Dir(), "count", len(entries))
+	return nil
+}
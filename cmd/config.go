@@ -0,0 +1,197 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"go.yaml.in/yaml/v3"
+)
+
+var (
+	configForce bool
+	configJSON  bool
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Manage the goscaffold config file",
+}
+
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Write a commented default config file",
+	Long:  `Write a commented config with the built-in defaults to --config, or $HOME/.goscaffold.yaml.`,
+	Args:  cobra.NoArgs,
+	RunE:  runConfigInit,
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective configuration",
+	Long: `Print the configuration in effect after merging defaults, the config file
+and GOSCAFFOLD_* environment variables (e.g. GOSCAFFOLD_BACKUP_RETENTION).`,
+	Args: cobra.NoArgs,
+	RunE: runConfigShow,
+}
+
+// configExamples documents the settings without defaults; it is appended
+// to the generated config.
+const configExamples = `
+# Settings without defaults:
+#
+# git:
+#   auto_init: true
+#   commit_trailers:   # appended to import commits
+#     - "Generated-by: goscaffold"
+#     - "Co-authored-by: AI <ai@example.com>"
+# new:
+#   template_overrides: $HOME/.goscaffold/templates
+#
+# validators:
+#   - extension: go
+#     command: go
+#     args: [vet]
+#     timeout: 30s
+#     strict: false
+#     stdin: false
+#     retries: 0           # extra attempts for transient failures
+#     retry_exit_codes: [] # exit codes treated as transient
+#
+# formatters:          # run after writing with import --format-on-import
+#   - extension: go
+#     command: gofmt
+#     args: [-w]
+#   - extension: py
+#     command: black
+#
+# templates:
+#   - name: api
+#     description: HTTP service
+#     structure:
+#       cmd:
+#         main.go: "@cmd/main.go"
+#       internal: {}
+#       go.mod: "@go.mod"
+`
+
+func init() {
+	configInitCmd.Flags().BoolVar(&configForce, "force", false, "Overwrite an existing config file")
+
+	configShowCmd.Flags().BoolVar(&configJSON, "json", false, "Print as JSON")
+
+	configCmd.AddCommand(configInitCmd, configShowCmd)
+	rootCmd.AddCommand(configCmd)
+}
+
+func runConfigInit(cmd *cobra.Command, args []string) error {
+	path := cfgFile
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return fmt.Errorf("find home directory: %w", err)
+		}
+		path = filepath.Join(home, ".goscaffold.yaml")
+	}
+
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !configForce {
+		flags |= os.O_EXCL
+	}
+	f, err := os.OpenFile(path, flags, 0644)
+	if errors.Is(err, fs.ErrExist) {
+		return fmt.Errorf("%s already exists (use --force)", path)
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.WriteString(defaultConfig()); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+
+	log.Info("✨ Config written", "path", path)
+	return nil
+}
+
+// defaultConfig renders defaults as commented YAML, one section per key
+// prefix.
+func defaultConfig() string {
+	var b strings.Builder
+	b.WriteString("# goscaffold configuration\n")
+
+	section := ""
+	for _, d := range defaults {
+		sec, key, _ := strings.Cut(d.key, ".")
+		if sec != section {
+			fmt.Fprintf(&b, "\n%s:\n", sec)
+			section = sec
+		}
+
+		value := fmt.Sprint(d.value)
+		if s, ok := d.value.(string); ok {
+			value = fmt.Sprintf("%q", s)
+		}
+		fmt.Fprintf(&b, "  # %s\n  %s: %s\n", d.help, key, value)
+	}
+
+	b.WriteString(configExamples)
+	return b.String()
+}
+
+func runConfigShow(cmd *cobra.Command, args []string) error {
+	file := viper.ConfigFileUsed()
+	env := envOverrides()
+	settings := viper.AllSettings()
+
+	if configJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			File     string                 `json:"file"`
+			Env      map[string]string      `json:"env"`
+			Settings map[string]interface{} `json:"settings"`
+		}{file, env, settings})
+	}
+
+	if file == "" {
+		file = "none"
+	}
+	fmt.Printf("# config file: %s\n", file)
+	for _, k := range slices.Sorted(maps.Keys(env)) {
+		fmt.Printf("# %s set by %s\n", k, env[k])
+	}
+
+	out, err := yaml.Marshal(settings)
+	if err != nil {
+		return err
+	}
+	fmt.Print(string(out))
+	return nil
+}
+
+// envOverrides maps each config key set through the environment to its
+// variable name.
+func envOverrides() map[string]string {
+	env := make(map[string]string)
+	for _, k := range viper.AllKeys() {
+		name := "GOSCAFFOLD_" + strings.ToUpper(strings.ReplaceAll(k, ".", "_"))
+		if _, ok := os.LookupEnv(name); ok {
+			env[k] = name
+		}
+	}
+	return env
+}
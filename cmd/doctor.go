@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"goscaffold/pkg/clipboard"
+	"goscaffold/pkg/config"
+	"goscaffold/pkg/git"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check that the tools goscaffold uses are available",
+	Long: `Check the config file, write access to the working directory, git, the
+clipboard tool and every configured validator command. Exits non-zero if a
+critical check fails; git and the clipboard are only needed by some flags.`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
+
+// check is one line of the doctor report.
+type check struct {
+	name     string
+	detail   string
+	err      error
+	optional bool
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+
+	var checks []check
+
+	cfg, err := doctorConfig()
+	checks = append(checks, check{name: "config", detail: configSource(), err: err})
+
+	wd, err := os.Getwd()
+	if err == nil {
+		err = checkWritable(wd)
+	}
+	checks = append(checks, check{name: "working directory", detail: wd, err: err})
+
+	version, err := git.Version(ctx)
+	checks = append(checks, check{name: "git", detail: version, err: err, optional: true})
+
+	tools := clipboard.Tools()
+	clip := check{name: "clipboard", optional: true}
+	for _, tool := range tools {
+		if path, err := exec.LookPath(tool); err == nil {
+			clip.detail = path
+			break
+		}
+	}
+	if clip.detail == "" {
+		clip.err = fmt.Errorf("none of %s found on PATH", strings.Join(tools, ", "))
+	}
+	checks = append(checks, clip)
+
+	if cfg != nil {
+		for _, v := range cfg.Validators {
+			c := check{name: "validator ." + strings.TrimPrefix(v.Extension, ".")}
+			c.detail, c.err = exec.LookPath(v.Command)
+			checks = append(checks, c)
+		}
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	var failed int
+	for _, c := range checks {
+		mark, detail := "✓", c.detail
+		if c.err != nil {
+			mark, detail = "✗", c.err.Error()
+			if c.optional {
+				detail += " (optional)"
+			} else {
+				failed++
+			}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", mark, c.name, detail)
+	}
+	tw.Flush()
+
+	if failed > 0 {
+		return fmt.Errorf("%d critical check(s) failed", failed)
+	}
+	return nil
+}
+
+// doctorConfig re-reads the config file so a parse error, which initConfig
+// only logs, fails the check.
+func doctorConfig() (*config.Config, error) {
+	if err := viper.ReadInConfig(); err != nil {
+		var notFound viper.ConfigFileNotFoundError
+		if !errors.As(err, &notFound) {
+			return nil, err
+		}
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	return cfg, cfg.Validate()
+}
+
+func configSource() string {
+	if f := viper.ConfigFileUsed(); f != "" {
+		return f
+	}
+	return "built-in defaults"
+}
+
+// checkWritable creates and removes a temporary file in dir.
+func checkWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".goscaffold-doctor-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
@@ -1,57 +1,178 @@
 package cmd
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
-	"golang.org/x/sync/errgroup"
 
 	"goscaffold/internal/models"
+	"goscaffold/pkg/archive"
 	"goscaffold/pkg/backup"
+	"goscaffold/pkg/charset"
 	"goscaffold/pkg/clipboard"
+	"goscaffold/pkg/diff"
+	"goscaffold/pkg/events"
+	"goscaffold/pkg/fetch"
+	"goscaffold/pkg/fsutil"
 	"goscaffold/pkg/git"
+	"goscaffold/pkg/glob"
+	"goscaffold/pkg/ignore"
 	"goscaffold/pkg/parser"
+	"goscaffold/pkg/scaffold"
 	"goscaffold/pkg/stats"
 	"goscaffold/pkg/ui"
 	"goscaffold/pkg/validator"
+	"goscaffold/pkg/webhook"
 )
 
 var (
 	dryRun       bool
 	useClipboard bool
-	inputFile    string
+	inputFiles   []string
+	inputURL     string
+	headers      []string
 	gitCommit    bool
 	interactive  bool
+	selectFiles  bool
 	backupFiles  bool
 	watchMode    bool
+	watchInitial bool
+	detectEnc    bool
+	inputEnc     string
+	dedupRuns    bool
+	saveUnnamed  bool
+	statsFormat  string
+	verbose      bool
+	concurrency  int
+	outputTar    string
+	failIfExists bool
+	gitBranch    string
+	branchReset  bool
+	gitPush      bool
+	requireClean bool
+	showDiff     bool
+	onConflict   string
+	strictValid  bool
+	onlyNew      bool
+	inputFormat  string
+	maxFileSize  string
+	maxFileBytes int64
+	maxTotal     int
+	maxTotalSize string
+	maxTotalB    int64
+	manifestPath string
+	topLangs     int
+	stripPrefix  string
+	stripComps   int
+	forceWrite   bool
+	formatFiles  bool
+	assumeYes    bool
+	allowDelete  bool
+	validScope   string
+	dryValidate  bool
+	listOnly     bool
+	emitEvents   bool
+	commitMsg    string
+	dupPolicy    string
+	webhookURL   string
+	webhookOn    string
+	traceFile    string
+	decodeB64    bool
+	varFlags     []string
+	expandVars   bool
+	templateVars map[string]string
+	excludes     []string
+	includes     []string
 )
 
 var importCmd = &cobra.Command{
-	Use:   "import [flags]",
+	Use:   "import [flags] [file...]",
 	Short: "Import AI-generated code blocks",
 	Long:  `Parse code blocks from input and create files. Supports markdown fences and clipboard.`,
 	Example: `  goscaffold import --clipboard
   goscaffold import --input chat.md --git-commit
-  cat output.md | goscaffold import -i -`,
+  goscaffold import part1.md part2.md
+  cat output.md | goscaffold import -i -
+  goscaffold import --url https://gist.githubusercontent.com/u/id/raw/chat.md`,
 	Aliases: []string{"i"},
 	RunE:    runImport,
 }
 
 func init() {
 	importCmd.Flags().BoolVarP(&dryRun, "dry-run", "d", false, "Preview without writing")
+	importCmd.Flags().BoolVar(&listOnly, "list", false, "Print each parsed file as path<TAB>bytes and exit without writing")
+	importCmd.Flags().BoolVar(&dryValidate, "validate", false, "With --dry-run, also run validators on each file's content")
 	importCmd.Flags().BoolVarP(&useClipboard, "clipboard", "c", false, "Read from clipboard")
-	importCmd.Flags().StringVarP(&inputFile, "input", "i", "", "Input file (- for stdin)")
-	importCmd.Flags().BoolVarP(&gitCommit, "git-commit", "g", false, "Auto-commit")
+	importCmd.Flags().StringArrayVarP(&inputFiles, "input", "i", nil, "Input file (- for stdin); repeat, or pass files as arguments, to merge several")
+	importCmd.Flags().StringVar(&inputURL, "url", "", "Fetch input over HTTP(S), e.g. a raw gist")
+	importCmd.Flags().StringArrayVar(&headers, "header", nil, "HTTP header for --url as \"Name: value\" (repeatable)")
+	importCmd.Flags().BoolVarP(&gitCommit, "git-commit", "g", false, "Commit imported files (default: git.auto_commit)")
+	importCmd.Flags().StringVarP(&commitMsg, "message", "M", "", "Commit message template, overriding git.commit_template")
+	importCmd.Flags().StringVar(&gitBranch, "git-branch", "", "Commit on this branch, creating it from HEAD if needed")
+	importCmd.Flags().BoolVar(&branchReset, "git-branch-reset", false, "Reset an existing --git-branch to HEAD instead of switching to it")
+	importCmd.Flags().BoolVar(&gitPush, "git-push", false, "Push after committing (implies --git-commit)")
+	importCmd.Flags().BoolVar(&requireClean, "git-require-clean", false, "Abort if files outside the import have uncommitted changes")
+	importCmd.Flags().BoolVar(&showDiff, "diff", false, "Show a unified diff for files that already exist")
+	importCmd.Flags().StringVar(&onConflict, "on-conflict", "overwrite", "How to handle existing files that differ (overwrite|skip|backup|merge)")
+	importCmd.Flags().BoolVar(&formatFiles, "format-on-import", false, "Run the configured formatter on each written file")
+	importCmd.Flags().BoolVar(&forceWrite, "force-write", false, "Rewrite files even when their content is unchanged")
+	importCmd.Flags().StringVar(&manifestPath, "manifest", "", "Write a JSON manifest of every file's outcome to this path")
+	importCmd.Flags().StringVar(&maxFileSize, "max-file-size", "1MiB", "Skip files larger than this, e.g. 512KiB or 2MB (0 = unlimited)")
+	importCmd.Flags().IntVar(&maxTotal, "max-total-files", 0, "Abort before writing if the input parses into more than N files (0 = unlimited)")
+	importCmd.Flags().StringVar(&maxTotalSize, "max-total-bytes", "0", "Abort before writing if the parsed files total more than this, e.g. 50MiB (0 = unlimited)")
+	importCmd.Flags().StringVar(&validScope, "validate-scope", "file", "Validate each file before writing it (file), or each directory's files together after all are written (package)")
+	importCmd.Flags().BoolVar(&strictValid, "strict-validate", false, "Do not write files that fail validation")
+	importCmd.Flags().StringArrayVar(&excludes, "exclude", nil, "Skip files matching this glob (repeatable; ** matches directories)")
+	importCmd.Flags().StringArrayVar(&includes, "include", nil, "Only import files matching this glob (repeatable)")
 	importCmd.Flags().BoolVarP(&interactive, "interactive", "I", false, "Interactive mode")
+	importCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Overwrite existing files without asking (see ui.confirm_create)")
+	importCmd.Flags().BoolVar(&selectFiles, "select", false, "Pick the files to write from a checklist (implies --interactive)")
 	importCmd.Flags().BoolVar(&backupFiles, "backup", viper.GetBool("backup.enabled"), "Create backups")
-	importCmd.Flags().BoolVarP(&watchMode, "watch", "w", false, "Watch file for changes")
+	importCmd.Flags().BoolVarP(&watchMode, "watch", "w", false, "Watch the --input file, or the .md files in an --input directory, for changes")
+	importCmd.Flags().BoolVar(&watchInitial, "watch-initial", false, "With --watch, also import the current content once at startup")
+	importCmd.Flags().BoolVar(&allowDelete, "allow-delete", false, "Apply delete and rename directives from the input (originals are backed up)")
+	importCmd.Flags().BoolVar(&onlyNew, "only-new", false, "Skip files that already exist")
+	importCmd.Flags().BoolVar(&failIfExists, "fail-if-exists", false, "Abort before writing if any target file already exists")
+	importCmd.Flags().StringVar(&outputTar, "output-tar", "", "Write files into this tar archive instead of the filesystem")
+	importCmd.Flags().IntVar(&concurrency, "concurrency", 4, "Files processed in parallel (default: import.concurrency; 1 gives deterministic log order)")
+	importCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Show the slowest files after import")
+	importCmd.Flags().IntVar(&topLangs, "top", 0, "Show only the N most common extensions in the statistics (0 = all)")
+	importCmd.Flags().BoolVar(&emitEvents, "events", false, "Write JSON-lines progress events (start, file, summary, end) to stderr")
+	importCmd.Flags().StringVar(&statsFormat, "stats-format", "table", "Statistics output format (table|text|json); text logs plain lines")
+	importCmd.Flags().StringVar(&stripPrefix, "strip-prefix", "", "Remove this leading directory from every path, e.g. myproject/")
+	importCmd.Flags().IntVar(&stripComps, "strip-components", 0, "Remove N leading path components (applied after --strip-prefix)")
+	importCmd.Flags().BoolVar(&decodeB64, "base64", false, "Decode blocks that declare \"encoding: base64\" before writing")
+	importCmd.Flags().StringArrayVar(&varFlags, "var", nil, "Template variable as key=value for --template-vars (repeatable)")
+	importCmd.Flags().BoolVar(&expandVars, "template-vars", false, "Render each file as a text/template with the --var values, e.g. {{.Module}}")
+	importCmd.Flags().StringVar(&webhookURL, "webhook", "", "POST a JSON summary of the import to this URL when it finishes")
+	importCmd.Flags().StringVar(&webhookOn, "webhook-on", "always", "When to call --webhook (always|failure)")
+	importCmd.Flags().StringVar(&traceFile, "trace-file", "", "Write every parser decision to this file as JSON lines, for debugging input that does not parse")
+	importCmd.Flags().StringVar(&dupPolicy, "dup-policy", "last", "When several blocks share a path: keep the last, the first, or fail (last|first|error)")
+	importCmd.Flags().StringVar(&inputFormat, "format", "auto", "Input format (markdown|yaml|diff|auto)")
+	importCmd.Flags().BoolVar(&saveUnnamed, "save-unnamed", false, "Save blocks without a path using parser.unnamed_strategy")
+	importCmd.Flags().BoolVar(&dedupRuns, "dedup-across-runs", false, "Skip files already written with identical content this session")
+	importCmd.Flags().BoolVar(&detectEnc, "input-encoding-detect", false, "Detect input encoding (BOM/heuristics) and transcode to UTF-8")
+	importCmd.Flags().StringVar(&inputEnc, "encoding", "", "Decode --input, --url and stdin as this encoding (utf-8|utf-16le|utf-16be|latin-1) instead of detecting it")
+
+	importCmd.MarkFlagsMutuallyExclusive("clipboard", "input", "url")
 
 	rootCmd.AddCommand(importCmd)
 }
@@ -59,31 +180,341 @@ func init() {
 func runImport(cmd *cobra.Command, args []string) error {
 	ctx := cmd.Context()
 
+	switch statsFormat {
+	case "table", "text", "json":
+	default:
+		return fmt.Errorf("invalid --stats-format %q (want table, text or json)", statsFormat)
+	}
+
+	if _, err := parser.ParseFormat(inputFormat); err != nil {
+		return err
+	}
+
+	if inputEnc != "" {
+		if _, err := charset.Parse(inputEnc); err != nil {
+			return fmt.Errorf("invalid --encoding: %w", err)
+		}
+	}
+
+	size, err := fsutil.ParseSize(maxFileSize)
+	if err != nil {
+		return fmt.Errorf("invalid --max-file-size: %w", err)
+	}
+	maxFileBytes = size
+
+	if maxTotal < 0 {
+		return fmt.Errorf("invalid --max-total-files %d (must be >= 0)", maxTotal)
+	}
+	if maxTotalB, err = fsutil.ParseSize(maxTotalSize); err != nil {
+		return fmt.Errorf("invalid --max-total-bytes: %w", err)
+	}
+
+	switch onConflict {
+	case "overwrite", "skip", "backup", "merge":
+	default:
+		return fmt.Errorf("invalid --on-conflict %q (want overwrite, skip, backup or merge)", onConflict)
+	}
+
+	if webhookOn != "always" && webhookOn != "failure" {
+		return fmt.Errorf("invalid --webhook-on %q (want always or failure)", webhookOn)
+	}
+
+	switch dupPolicy {
+	case "last", "first", "error":
+	default:
+		return fmt.Errorf("invalid --dup-policy %q (want last, first or error)", dupPolicy)
+	}
+
+	if validScope != "file" && validScope != "package" {
+		return fmt.Errorf("invalid --validate-scope %q (want file or package)", validScope)
+	}
+
+	for _, p := range append(append([]string{}, excludes...), includes...) {
+		if err := glob.Valid(p); err != nil {
+			return fmt.Errorf("invalid glob %q: %w", p, err)
+		}
+	}
+
+	templateVars = make(map[string]string, len(varFlags))
+	for _, kv := range varFlags {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || strings.TrimSpace(k) == "" {
+			return fmt.Errorf("invalid --var %q (want key=value)", kv)
+		}
+		templateVars[strings.TrimSpace(k)] = v
+	}
+
+	emitter = nil
+	if emitEvents {
+		emitter = events.New(os.Stderr)
+	}
+
+	traceEnc = nil
+	if traceFile != "" {
+		f, err := os.Create(traceFile)
+		if err != nil {
+			return fmt.Errorf("open --trace-file: %w", err)
+		}
+		defer f.Close()
+		traceEnc = json.NewEncoder(f)
+	}
+
+	if ignoreRules, err = ignore.Load(ignore.File); err != nil {
+		return fmt.Errorf("load %s: %w", ignore.File, err)
+	}
+
+	gitCommit = commitEnabled(cmd)
+	if selectFiles {
+		interactive = true
+	}
+
+	inputFiles = append(inputFiles, args...)
+	if len(args) > 0 && (useClipboard || inputURL != "") {
+		return fmt.Errorf("input files cannot be combined with --clipboard or --url")
+	}
+
+	if !cmd.Flags().Changed("concurrency") {
+		concurrency = viper.GetInt("import.concurrency")
+	}
+	if concurrency < 1 {
+		return fmt.Errorf("invalid concurrency %d (must be >= 1)", concurrency)
+	}
+
 	if watchMode {
 		return runWatchMode(ctx)
 	}
 
-	content, err := getInput(ctx)
+	files, err := loadFiles(ctx)
 	if err != nil {
-		return fmt.Errorf("input error: %w", err)
+		return err
+	}
+	if listOnly {
+		listFiles(os.Stdout, files)
+		return nil
 	}
-
-	files := parser.Parse(content)
 	if len(files) == 0 {
 		return fmt.Errorf("no valid code blocks found")
 	}
+	if err := checkBudget(files); err != nil {
+		return err
+	}
 
 	log.Info(fmt.Sprintf("Found %d files", len(files)))
 
+	emitter.Start(len(files))
+	err = importFiles(ctx, files)
+	emitter.End(err)
+	notifyWebhook(ctx, err)
+	return err
+}
+
+// notifyWebhook posts the outcome of the import to --webhook. Failing to
+// deliver it is only a warning.
+func notifyWebhook(ctx context.Context, importErr error) {
+	if webhookURL == "" || (webhookOn == "failure" && importErr == nil) {
+		return
+	}
+
+	p := webhook.Payload{OK: importErr == nil}
+	if importErr != nil {
+		p.Error = importErr.Error()
+		p.Text = "goscaffold import failed: " + p.Error
+	} else {
+		p.Text = "goscaffold import succeeded"
+	}
+	if lastStats != nil {
+		var buf bytes.Buffer
+		if err := lastStats.WriteJSON(&buf); err == nil {
+			p.Stats = buf.Bytes()
+		}
+		p.Text += " (" + lastStats.Summary() + ")"
+	}
+
+	// Report even an interrupted import.
+	if err := webhook.Send(context.WithoutCancel(ctx), webhookURL, p); err != nil {
+		log.Warn("Webhook failed", "error", err)
+		return
+	}
+	log.Debug("Webhook sent", "url", webhookURL)
+}
+
+// importFiles writes, previews or archives the parsed files as the flags
+// say.
+func importFiles(ctx context.Context, files []models.File) error {
+	if failIfExists && outputTar == "" {
+		if err := checkNoneExist(files); err != nil {
+			return err
+		}
+	}
+
 	if dryRun {
-		return runDryRun(files)
+		return runDryRun(ctx, files)
+	}
+
+	if gitCommit && outputTar == "" {
+		if err := checkWorkingTree(ctx, files); err != nil {
+			return err
+		}
+	}
+
+	if outputTar != "" {
+		return runTar(files)
 	}
 
 	if interactive {
-		return ui.RunInteractive(files, gitCommit, backupFiles)
+		return runInteractive(ctx, files)
+	}
+
+	return runBatch(ctx, files, nil)
+}
+
+// checkBudget enforces --max-total-files and --max-total-bytes on the whole
+// parsed set, so a pathological input is refused before anything is written.
+func checkBudget(files []models.File) error {
+	total := 0
+	for _, f := range files {
+		total += len(f.Code)
+	}
+	if maxTotal > 0 && len(files) > maxTotal {
+		return fmt.Errorf("input has %d files (%d bytes), over --max-total-files %d", len(files), total, maxTotal)
+	}
+	if maxTotalB > 0 && int64(total) > maxTotalB {
+		return fmt.Errorf("input has %d files totalling %d bytes, over --max-total-bytes %d", len(files), total, maxTotalB)
+	}
+	return nil
+}
+
+// listFiles prints one plain line per file for --list. Deletes and renames
+// show the action in place of a size.
+func listFiles(w io.Writer, files []models.File) {
+	for _, f := range files {
+		switch f.Action {
+		case models.ActionDelete:
+			fmt.Fprintf(w, "%s\tdelete\n", f.Path)
+		case models.ActionRename:
+			fmt.Fprintf(w, "%s\trename\t%s\n", f.Path, f.NewPath)
+		default:
+			fmt.Fprintf(w, "%s\t%d\n", f.Path, len(f.Code))
+		}
+	}
+}
+
+// shouldConfirmOverwrites reports whether runBatch should ask before
+// overwriting: ui.confirm_create is set, neither --yes nor --quiet was given,
+// existing files would actually be replaced and stdin is a terminal to ask on.
+func shouldConfirmOverwrites() bool {
+	return viper.GetBool("ui.confirm_create") && !assumeYes && !quiet &&
+		onConflict != "skip" && isTerminal(os.Stdin)
+}
+
+// confirmOverwrites asks y/N for each file that would replace an existing
+// file with different content, and drops the declined ones as skipped. New
+// and unchanged files are kept without asking.
+func confirmOverwrites(files []models.File, s *stats.Stats) ([]models.File, error) {
+	in := bufio.NewReader(os.Stdin)
+	yesAll, noAll := false, false
+
+	var kept []models.File
+	for _, f := range files {
+		if !f.Writes() {
+			kept = append(kept, f)
+			continue
+		}
+		same, err := fsutil.SameContent(f.Path, []byte(f.Code))
+		if errors.Is(err, fs.ErrNotExist) || same || yesAll {
+			kept = append(kept, f)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", f.Path, err)
+		}
+
+		choice := ui.No
+		if !noAll {
+			if choice, err = ui.Ask(in, os.Stdout, fmt.Sprintf("Overwrite %s?", f.Path)); err != nil {
+				return nil, err
+			}
+		}
+
+		switch choice {
+		case ui.Yes:
+			kept = append(kept, f)
+		case ui.All:
+			yesAll = true
+			kept = append(kept, f)
+		case ui.Quit:
+			noAll = true
+			s.AddSkipped(f.Path)
+		default:
+			s.AddSkipped(f.Path)
+		}
+	}
+	return kept, nil
+}
+
+// dropExisting removes files whose path already exists for --only-new,
+// counting them as skipped in s.
+func dropExisting(files []models.File, s *stats.Stats) []models.File {
+	var kept []models.File
+	for _, f := range files {
+		if _, err := os.Stat(f.Path); err == nil {
+			s.AddSkipped(f.Path)
+			continue
+		}
+		kept = append(kept, f)
+	}
+
+	if n := len(files) - len(kept); n > 0 {
+		log.Info(fmt.Sprintf("Skipping %d existing file(s)", n))
+	}
+	return kept
+}
+
+func checkNoneExist(files []models.File) error {
+	var conflicts []string
+	for _, f := range files {
+		if !f.Writes() {
+			continue
+		}
+		if _, err := os.Stat(f.Path); err == nil {
+			conflicts = append(conflicts, f.Path)
+		}
+	}
+	if len(conflicts) > 0 {
+		return fmt.Errorf("%d target file(s) already exist: %s", len(conflicts), strings.Join(conflicts, ", "))
+	}
+	return nil
+}
+
+// checkWorkingTree warns, or errors with --git-require-clean, when files
+// outside the import already have uncommitted changes.
+func checkWorkingTree(ctx context.Context, files []models.File) error {
+	dirty, err := git.DirtyFiles(ctx)
+	if err != nil {
+		log.Warn("Could not check git status", "error", err)
+		return nil
+	}
+
+	imported := make(map[string]bool, len(files))
+	for _, f := range files {
+		imported[filepath.Clean(f.Path)] = true
+	}
+
+	var extra []string
+	for _, p := range dirty {
+		if !imported[p] {
+			extra = append(extra, p)
+		}
+	}
+	if len(extra) == 0 {
+		return nil
 	}
 
-	return runBatch(ctx, files)
+	if requireClean {
+		return fmt.Errorf("working tree has %d uncommitted file(s) outside the import: %s", len(extra), strings.Join(extra, ", "))
+	}
+	log.Warn("Working tree has uncommitted changes outside the import; only imported files will be committed", "files", strings.Join(extra, ", "))
+	return nil
 }
 
 func getInput(ctx context.Context) (string, error) {
@@ -91,15 +522,16 @@ func getInput(ctx context.Context) (string, error) {
 		return clipboard.Read()
 	}
 
-	if inputFile != "" {
-		if inputFile == "-" {
-			return readStdin()
-		}
-		data, err := os.ReadFile(inputFile)
+	if inputURL != "" {
+		data, err := fetch.Get(ctx, inputURL, headers)
 		if err != nil {
 			return "", err
 		}
-		return string(data), nil
+		return decodeInput(data)
+	}
+
+	if len(inputFiles) == 1 {
+		return readInputFile(inputFiles[0])
 	}
 
 	if content, _ := clipboard.Read(); content != "" {
@@ -110,6 +542,219 @@ func getInput(ctx context.Context) (string, error) {
 	return "", fmt.Errorf("no input source specified")
 }
 
+func readInputFile(path string) (string, error) {
+	if path == "-" {
+		return readStdin()
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return decodeInput(data)
+}
+
+// loadFiles reads and parses the input. Several input files, --input ones
+// first and then arguments, are parsed one by one and merged. Blocks that
+// share a path are resolved by --dup-policy.
+func loadFiles(ctx context.Context) ([]models.File, error) {
+	if len(inputFiles) <= 1 {
+		content, err := getInput(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("input error: %w", err)
+		}
+		files, err := parseInput(content)
+		if err != nil {
+			return nil, err
+		}
+		return dedupFiles(files)
+	}
+
+	var all []models.File
+	for _, src := range inputFiles {
+		content, err := readInputFile(src)
+		if err != nil {
+			return nil, fmt.Errorf("input error: %s: %w", src, err)
+		}
+		traceSource = src
+		files, err := parseInput(content)
+		if err != nil {
+			return nil, err
+		}
+		for i := range files {
+			files[i].Source = src
+		}
+		all = append(all, files...)
+	}
+
+	merged, err := dedupFiles(all)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range merged {
+		if verbose {
+			log.Info("Parsed file", "path", f.Path, "source", f.Source)
+		} else {
+			log.Debug("Parsed file", "path", f.Path, "source", f.Source)
+		}
+	}
+	return merged, nil
+}
+
+// dedupFiles keeps one file per path, as --dup-policy says, so no two
+// concurrent writes target the same file. A kept duplicate stays at the
+// position of the path's first occurrence.
+func dedupFiles(files []models.File) ([]models.File, error) {
+	byPath := make(map[string]int, len(files))
+	var kept []models.File
+	for _, f := range files {
+		i, ok := byPath[f.Path]
+		if !ok {
+			byPath[f.Path] = len(kept)
+			kept = append(kept, f)
+			continue
+		}
+
+		prev := kept[i]
+		switch dupPolicy {
+		case "error":
+			return nil, fmt.Errorf("%s is defined more than once (%s and %s)", f.Path, fileOrigin(prev), fileOrigin(f))
+		case "first":
+			log.Warn("Duplicate path, keeping the first", "path", f.Path, "kept", fileOrigin(prev), "dropped", fileOrigin(f))
+		default:
+			log.Warn("Duplicate path, keeping the last", "path", f.Path, "kept", fileOrigin(f), "dropped", fileOrigin(prev))
+			kept[i] = f
+		}
+	}
+	return kept, nil
+}
+
+// fileOrigin describes where a file was parsed from, e.g. "chat.md:12".
+func fileOrigin(f models.File) string {
+	origin := fmt.Sprintf("line %d", f.StartLine)
+	if f.Source != "" {
+		origin = fmt.Sprintf("%s:%d", f.Source, f.StartLine)
+	}
+	return origin
+}
+
+var (
+	// traceEnc writes --trace-file records; it is nil without the flag.
+	traceEnc *json.Encoder
+	// traceSource names the input being parsed when there are several.
+	traceSource string
+)
+
+// traceParse records one parser decision to --trace-file.
+func traceParse(line int, event, detail string) {
+	err := traceEnc.Encode(struct {
+		Source string `json:"source,omitempty"`
+		Line   int    `json:"line"`
+		Event  string `json:"event"`
+		Detail string `json:"detail,omitempty"`
+	}{traceSource, line, event, detail})
+	if err != nil {
+		log.Debug("Writing trace failed", "error", err)
+	}
+}
+
+func parseInput(content string) ([]models.File, error) {
+	format, err := parser.ParseFormat(inputFormat)
+	if err != nil {
+		return nil, err
+	}
+	opts := parser.Options{Format: format, StripPrefix: stripPrefix, StripComponents: stripComps, Base64: decodeB64}
+	if saveUnnamed {
+		namer, err := parser.NamerFor(viper.GetString("parser.unnamed_strategy"))
+		if err != nil {
+			return nil, err
+		}
+		opts.Unnamed = namer
+	}
+	opts.DropInvalid = true
+	if traceEnc != nil {
+		opts.Trace = traceParse
+	}
+
+	files, dropped := parser.ParseWithOptions(content, opts)
+	for _, f := range dropped {
+		log.Warn("Skipping file", "path", f.Path, "line", f.StartLine, "reason", strings.Join(f.Warnings, "; "))
+	}
+	for _, f := range files {
+		for _, w := range f.Warnings {
+			log.Warn("Parse warning", "path", f.Path, "warning", w)
+		}
+	}
+	files = filterFiles(files)
+	if expandVars {
+		files = renderVars(files)
+	}
+	return files, nil
+}
+
+// renderVars executes each file's code as a text/template with
+// templateVars. A file that fails to render, including one that uses a
+// variable with no --var, is skipped with the error.
+func renderVars(files []models.File) []models.File {
+	var kept []models.File
+	for _, f := range files {
+		if !f.Writes() {
+			kept = append(kept, f)
+			continue
+		}
+		t, err := template.New(f.Path).Option("missingkey=error").Parse(f.Code)
+		if err == nil {
+			var b strings.Builder
+			if err = t.Execute(&b, templateVars); err == nil {
+				f.Code = b.String()
+			}
+		}
+		if err != nil {
+			log.Warn("Skipping file", "path", f.Path, "line", f.StartLine, "reason", err)
+			continue
+		}
+		kept = append(kept, f)
+	}
+	return kept
+}
+
+// ignoreRules are loaded from .goscaffoldignore by runImport.
+var ignoreRules *ignore.Matcher
+
+// filterFiles applies .goscaffoldignore, then --include and --exclude.
+// Patterns are checked up front in runImport.
+func filterFiles(files []models.File) []models.File {
+	var kept []models.File
+	for _, f := range files {
+		if rule, ok := ignoreRules.Match(f.Path); ok {
+			log.Warn("Ignored by "+ignore.File, "path", f.Path, "rule", rule)
+			continue
+		}
+		if len(includes) > 0 && !matchAny(includes, f.Path) {
+			log.Debug("Not included", "path", f.Path)
+			continue
+		}
+		if matchAny(excludes, f.Path) {
+			log.Debug("Excluded", "path", f.Path)
+			continue
+		}
+		kept = append(kept, f)
+	}
+
+	if n := len(files) - len(kept); n > 0 {
+		log.Info(fmt.Sprintf("Filtered out %d file(s)", n))
+	}
+	return kept
+}
+
+func matchAny(patterns []string, path string) bool {
+	for _, p := range patterns {
+		if ok, _ := glob.Match(p, path); ok {
+			return true
+		}
+	}
+	return false
+}
+
 func readStdin() (string, error) {
 	stat, _ := os.Stdin.Stat()
 	if (stat.Mode() & os.ModeCharDevice) != 0 {
@@ -120,115 +765,708 @@ func readStdin() (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return string(data), nil
+	return decodeInput(data)
+}
+
+// decodeInput converts raw input to UTF-8: as --encoding says, detected
+// with --input-encoding-detect, or otherwise taken as UTF-8. The parser
+// strips a UTF-8 BOM either way.
+func decodeInput(data []byte) (string, error) {
+	if inputEnc != "" {
+		enc, _ := charset.Parse(inputEnc) // checked in runImport
+		content, err := charset.Decode(charset.TrimBOM(data, enc), enc)
+		if err != nil {
+			return "", fmt.Errorf("decode input as %s: %w", enc, err)
+		}
+		return content, nil
+	}
+	if !detectEnc {
+		return string(data), nil
+	}
+
+	content, enc, err := charset.ToUTF8(data)
+	if err != nil {
+		return "", fmt.Errorf("decode input: %w", err)
+	}
+	log.Debug("Detected input encoding", "encoding", enc)
+	return content, nil
 }
 
-func runDryRun(files []models.File) error {
+func runDryRun(ctx context.Context, files []models.File) error {
 	log.Info("=== DRY RUN ===")
+	if onlyNew {
+		files = dropExisting(files, stats.New())
+	}
+
+	// With --validate, content is checked as import would before writing;
+	// validators see it on stdin or in a temporary file, never the target.
+	var invalid, strictInvalid int
+	check := func(f models.File) string {
+		if !dryValidate {
+			return ""
+		}
+		v, err := validator.GetForFile(f.TypedPath())
+		if err != nil {
+			return ""
+		}
+		if err := v.Validate(ctx, f.TypedPath(), f.Code); err != nil {
+			if ctx.Err() != nil {
+				return ""
+			}
+			invalid++
+			if strictValid || v.Strict {
+				strictInvalid++
+			}
+			return " ✗ " + strings.ReplaceAll(strings.TrimSpace(err.Error()), "\n", " ")
+		}
+		return " ✓ valid"
+	}
+
+	var created, modified, unchanged, moved int
 	for _, f := range files {
-		action := "create"
-		if _, err := os.Stat(f.Path); err == nil {
-			action = "update"
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !f.Writes() {
+			moved++
+			log.Info(fmt.Sprintf("%-9s %s", strings.ToUpper(string(f.Action)), describeTarget(f)))
+			continue
 		}
-		log.Info(fmt.Sprintf("Would %s: %s (%d bytes)", action, f.Path, len(f.Code)))
+
+		old, err := os.ReadFile(f.Path)
+		switch {
+		case err != nil:
+			created++
+			log.Info(fmt.Sprintf("NEW       %s (%d bytes, %d lines)%s", f.Path, len(f.Code), countLines(f.Code), check(f)))
+		case string(old) == f.Code:
+			unchanged++
+			log.Info(fmt.Sprintf("UNCHANGED %s", f.Path))
+		default:
+			modified++
+			log.Info(fmt.Sprintf("MODIFIED  %s (%d → %d bytes, %+d lines)%s", f.Path,
+				len(old), len(f.Code), countLines(f.Code)-countLines(string(old)), check(f)))
+			if showDiff {
+				printDiff(f)
+			}
+		}
+	}
+	summary := fmt.Sprintf("%d new, %d modified, %d unchanged", created, modified, unchanged)
+	if moved > 0 {
+		summary += fmt.Sprintf(", %d deleted or renamed", moved)
+		if !allowDelete {
+			summary += " (needs --allow-delete)"
+		}
+	}
+	if dryValidate {
+		summary += fmt.Sprintf(", %d would fail validation", invalid)
+	}
+	log.Info(summary)
+
+	if strictInvalid > 0 {
+		return fmt.Errorf("%d file(s) would fail strict validation", strictInvalid)
 	}
 	return nil
 }
 
-func runBatch(ctx context.Context, files []models.File) error {
-	s := stats.New()
-	bm := backup.NewManager(viper.GetString("backup.retention"))
+func countLines(s string) int {
+	n := strings.Count(s, "\n")
+	if s != "" && !strings.HasSuffix(s, "\n") {
+		n++
+	}
+	return n
+}
+
+// printDiff prints the diff between the file on disk and f, reporting
+// whether f.Path existed.
+func printDiff(f models.File) bool {
+	old, err := os.ReadFile(f.Path)
+	if err != nil {
+		return false
+	}
 
-	g, ctx := errgroup.WithContext(ctx)
-	g.SetLimit(4)
+	d := diff.Unified(string(old), f.Code, f.Path)
+	if d == "" {
+		fmt.Printf("%s: no changes\n", f.Path)
+	} else {
+		if useColor {
+			d = diff.Colorize(d)
+		}
+		fmt.Print(d)
+	}
+	return true
+}
+
+func runTar(files []models.File) error {
+	f, err := os.Create(outputTar)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	s := newStats()
+	var kept []models.File
+	for _, file := range files {
+		if !file.Writes() {
+			log.Warn("Archives only hold written files, skipping", "action", file.Action, "path", file.Path)
+			s.AddSkipped(file.Path)
+			continue
+		}
+		kept = append(kept, file)
+	}
+	files = kept
+
+	if err := archive.WriteTar(f, files); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("close %s: %w", outputTar, err)
+	}
 
 	for _, file := range files {
-		f := file
-		g.Go(func() error {
-			return processFile(ctx, f, s, bm)
-		})
+		s.AddFile(stats.FileStat{Path: file.Path, Bytes: len(file.Code), Language: strings.TrimPrefix(file.Ext(), ".")})
+	}
+	printStats(s)
+	writeManifest(s)
+
+	log.Info("✨ Archive written", "path", outputTar)
+	return nil
+}
+
+// runBatch writes files without prompting per file. onFile, when set, is
+// called as each file is handled, as scaffold.Options.OnFile is.
+func runBatch(ctx context.Context, files []models.File, onFile func(models.File, bool)) error {
+	s := newStats()
+	bm, err := newBackupManager()
+	if err != nil {
+		return err
+	}
+	j := backup.NewSession()
+	if onlyNew {
+		files = dropExisting(files, s)
+	}
+	if shouldConfirmOverwrites() {
+		var err error
+		if files, err = confirmOverwrites(files, s); err != nil {
+			return err
+		}
+	}
+
+	var bar *ui.Progress
+	if !quiet && isTerminal(os.Stderr) {
+		bar = ui.NewProgress(os.Stderr, len(files))
 	}
 
-	if err := g.Wait(); err != nil {
+	im := newImporter(bm, j, func(f models.File, written bool) {
+		bar.Inc()
+		if onFile != nil {
+			onFile(f, written)
+		}
+	})
+	written, err := im.Write(ctx, files, s)
+	bar.Finish()
+	if ctx.Err() != nil {
+		saveSession(bm, j)
+		printStats(s)
+		writeManifest(s)
+		return fmt.Errorf("import cancelled: %w", ctx.Err())
+	}
+	if err != nil {
+		saveSession(bm, j)
 		return fmt.Errorf("processing failed: %w", err)
 	}
 
-	s.Print()
+	var paths []string
+	for _, f := range written {
+		paths = append(paths, touchedPaths(f)...)
+	}
+
+	verr := validatePackages(ctx, im, written)
+	finishImport(ctx, s, bm, j, paths)
+	if verr != nil {
+		return verr
+	}
+	return failedErr(s)
+}
 
-	if gitCommit && s.TotalFiles > 0 {
-		log.Info("Committing to git...")
-		paths := make([]string, len(files))
-		for i, f := range files {
-			paths[i] = f.Path
+func failedErr(s *stats.Stats) error {
+	if s.Failed > 0 {
+		return fmt.Errorf("%d file(s) failed validation", s.Failed)
+	}
+	return nil
+}
+
+func runInteractive(ctx context.Context, files []models.File) error {
+	s := newStats()
+	bm, err := newBackupManager()
+	if err != nil {
+		return err
+	}
+	j := backup.NewSession()
+	if onlyNew {
+		files = dropExisting(files, s)
+	}
+	in := bufio.NewReader(os.Stdin)
+
+	// With --select the checklist replaces the per-file prompts.
+	all := false
+	if selectFiles {
+		chosen, err := ui.Select(in, os.Stdout, files)
+		if err != nil {
+			return err
 		}
-		if err := git.Commit(ctx, paths, "chore(scaffold): import AI files"); err != nil {
-			log.Warn("Git commit failed", "error", err)
+		picked := make(map[string]bool, len(chosen))
+		for _, f := range chosen {
+			picked[f.Path] = true
+		}
+		for _, f := range files {
+			if !picked[f.Path] {
+				s.AddSkipped(f.Path)
+			}
 		}
+		files = chosen
+		all = true
+	}
+
+	im := newImporter(bm, j, nil)
+	var (
+		paths   []string
+		written []models.File
+	)
+loop:
+	for i, f := range files {
+		if !all {
+			question := fmt.Sprintf("[%d/%d] %s?", i+1, len(files), describeAction(f))
+			if f.Writes() && (!showDiff || !printDiff(f)) {
+				ui.ShowFilePreview(os.Stdout, f)
+			}
+			choice, err := ui.Ask(in, os.Stdout, question)
+			if err != nil {
+				return err
+			}
+
+			switch choice {
+			case ui.No:
+				s.AddSkipped(f.Path)
+				continue
+			case ui.Quit:
+				for _, rest := range files[i:] {
+					s.AddSkipped(rest.Path)
+				}
+				break loop
+			case ui.All:
+				all = true
+			}
+		}
+
+		ok, err := im.WriteFile(ctx, f, s)
+		if err != nil {
+			saveSession(bm, j)
+			return err
+		}
+		if ok {
+			paths = append(paths, touchedPaths(f)...)
+			written = append(written, f)
+		}
+	}
+
+	verr := validatePackages(ctx, im, written)
+	finishImport(ctx, s, bm, j, paths)
+	if verr != nil {
+		return verr
+	}
+	return failedErr(s)
+}
+
+// finishImport reports stats, journals the session, commits the written
+// paths and prunes backups.
+func finishImport(ctx context.Context, s *stats.Stats, bm *backup.Manager, j *backup.Session, paths []string) {
+	printStats(s)
+	writeManifest(s)
+	saveSession(bm, j)
+
+	if gitCommit && s.TotalFiles > 0 {
+		commitImport(ctx, s, paths)
+	}
+
+	if err := bm.Prune(); err != nil {
+		log.Warn("Backup prune failed", "error", err)
 	}
 
 	log.Info("✨ Import complete")
-	return nil
 }
 
-func processFile(ctx context.Context, file models.File, s *stats.Stats, bm *backup.Manager) error {
-	if backupFiles {
-		_ = bm.Backup(file.Path)
+// saveSession journals j so the import can be reverted with undo.
+func saveSession(bm *backup.Manager, j *backup.Session) {
+	if j.Empty() {
+		return
+	}
+	if err := bm.SaveSession(j); err != nil {
+		log.Warn("Saving import session failed", "error", err)
+		return
+	}
+	log.Debug("Saved import session", "id", j.ID)
+}
+
+const defaultCommitMessage = "chore(scaffold): import AI files"
+
+// commitData is what --message and git.commit_template can refer to.
+type commitData struct {
+	FileCount   int
+	Created     int
+	Overwritten int
+	Deleted     int
+	Renamed     int
+	Bytes       int
+	Languages   string // comma-separated, most common first
+	Date        string // YYYY-MM-DD
+}
+
+// commitEnabled reports whether imported files are committed: --git-commit
+// when given, otherwise git.auto_commit. --git-push implies committing.
+func commitEnabled(cmd *cobra.Command) bool {
+	commit := gitCommit
+	if !cmd.Flags().Changed("git-commit") {
+		commit = viper.GetBool("git.auto_commit")
+	}
+	return commit || gitPush
+}
+
+// commitMessage renders --message, or else git.commit_template, with the
+// run's stats. A template that fails to render falls back to the default.
+func commitMessage(s *stats.Stats) string {
+	tmpl := commitMsg
+	if tmpl == "" {
+		tmpl = viper.GetString("git.commit_template")
+	}
+	if strings.TrimSpace(tmpl) == "" {
+		return defaultCommitMessage
+	}
+
+	data := commitData{
+		FileCount:   s.TotalFiles,
+		Created:     s.Created,
+		Overwritten: s.Overwritten,
+		Deleted:     s.Deleted,
+		Renamed:     s.Renamed,
+		Bytes:       s.TotalBytes,
+		Languages:   strings.Join(s.LanguageNames(), ", "),
+		Date:        time.Now().Format(time.DateOnly),
+	}
+	t, err := template.New("commit").Option("missingkey=error").Parse(tmpl)
+	var b strings.Builder
+	if err == nil {
+		err = t.Execute(&b, data)
+	}
+	if err != nil || strings.TrimSpace(b.String()) == "" {
+		log.Warn("Commit message template failed, using default", "error", err)
+		return defaultCommitMessage
+	}
+	return b.String()
+}
+
+func commitImport(ctx context.Context, s *stats.Stats, paths []string) {
+	if gitBranch != "" {
+		if err := checkoutBranch(ctx, gitBranch); err != nil {
+			log.Warn("Git branch failed, skipping commit", "branch", gitBranch, "error", err)
+			return
+		}
+	}
+
+	log.Info("Committing to git...")
+	msg := git.WithTrailers(commitMessage(s), viper.GetStringSlice("git.commit_trailers"))
+	if err := git.Commit(ctx, paths, msg); errors.Is(err, git.ErrNothingToCommit) {
+		log.Info("Nothing to commit")
+		return
+	} else if err != nil {
+		log.Warn("Git commit failed", "error", err)
+		return
+	}
+
+	if gitPush {
+		pushImport(ctx)
+	}
+}
+
+// pushImport pushes the current branch. A failed push leaves the local
+// commit in place.
+func pushImport(ctx context.Context) {
+	branch, err := git.CurrentBranch(ctx)
+	if err != nil {
+		log.Error("Git push failed", "error", err)
+		return
+	}
+
+	remote := viper.GetString("git.remote")
+	log.Info("Pushing to git...", "remote", remote, "branch", branch)
+	if err := git.Push(ctx, remote, branch); err != nil {
+		log.Error("Git push failed; the local commit was kept", "remote", remote, "branch", branch, "error", err)
+	}
+}
+
+func checkoutBranch(ctx context.Context, name string) error {
+	current, err := git.CurrentBranch(ctx)
+	if err != nil {
+		return err
+	}
+	if current == name && !branchReset {
+		return nil
+	}
+
+	exists, err := git.BranchExists(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case !exists:
+		log.Info("Creating branch", "branch", name, "from", current)
+		return git.CreateBranch(ctx, name)
+	case branchReset:
+		log.Info("Resetting branch", "branch", name, "to", current)
+		return git.ResetBranch(ctx, name)
+	default:
+		log.Info("Switching to branch", "branch", name)
+		return git.SwitchBranch(ctx, name)
 	}
+}
 
-	dir := filepath.Dir(file.Path)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return fmt.Errorf("mkdir %s: %w", dir, err)
+// writeManifest writes s's per-file records to --manifest, if set.
+func writeManifest(s *stats.Stats) {
+	if manifestPath == "" {
+		return
 	}
 
-	if v, err := validator.Get(file.Path); err == nil {
-		if err := v.Validate(ctx, file.Path, file.Code); err != nil {
-			log.Warn("Validation warning", "file", file.Path, "error", err)
+	var buf bytes.Buffer
+	if err := s.WriteManifest(&buf); err != nil {
+		log.Warn("Writing manifest failed", "error", err)
+		return
+	}
+	if err := fsutil.WriteAtomic(manifestPath, buf.Bytes(), 0644); err != nil {
+		log.Warn("Writing manifest failed", "error", err)
+		return
+	}
+	log.Debug("Wrote manifest", "path", manifestPath)
+}
+
+// emitter writes --events; it is nil, discarding events, without the flag.
+var emitter *events.Emitter
+
+// lastStats is the Stats of the latest import, for --webhook.
+var lastStats *stats.Stats
+
+// newStats returns the Stats for one import, reporting each file to
+// emitter.
+func newStats() *stats.Stats {
+	s := stats.New()
+	if emitter != nil {
+		s.OnRecord = emitter.File
+	}
+	lastStats = s
+	return s
+}
+
+func printStats(s *stats.Stats) {
+	emitter.Summary(s)
+
+	if statsFormat == "json" {
+		if err := s.WriteJSON(os.Stdout); err != nil {
+			log.Warn("Writing stats failed", "error", err)
 		}
+		return
 	}
 
-	if err := os.WriteFile(file.Path, []byte(file.Code), 0644); err != nil {
-		return fmt.Errorf("write %s: %w", file.Path, err)
+	if quiet && !debug && !trace {
+		fmt.Println(s.Summary())
+		return
 	}
 
-	s.AddFile(file.Path, file.Code)
-	log.Debug("Created file", "path", file.Path, "size", len(file.Code))
+	if statsFormat == "table" {
+		if err := s.WriteTable(os.Stderr, topLangs, useColor && isTerminal(os.Stderr)); err != nil {
+			log.Warn("Writing stats failed", "error", err)
+		}
+	} else {
+		s.Print(topLangs)
+	}
+	if verbose {
+		log.Info("Slowest files:")
+		for _, t := range s.Slowest(5) {
+			log.Info(fmt.Sprintf("  %s: %s", t.Path, t.Duration.Round(time.Microsecond)))
+		}
+		log.Info("Largest files:")
+		for _, f := range s.Largest(5) {
+			log.Info(fmt.Sprintf("  %s: %d bytes", f.Path, f.Bytes))
+		}
+	}
+}
+
+// newImporter configures a scaffold.Importer from the import flags.
+func newImporter(bm *backup.Manager, j *backup.Session, onFile func(models.File, bool)) *scaffold.Importer {
+	return scaffold.New(scaffold.Options{
+		Concurrency:   concurrency,
+		Backup:        bm,
+		BackupAll:     backupFiles,
+		Journal:       j,
+		OnConflict:    onConflict,
+		ValidateScope: validScope,
+		Strict:        strictValid,
+		MaxFileSize:   maxFileBytes,
+		Force:         forceWrite,
+		Format:        formatFiles,
+		AllowDelete:   allowDelete,
+		OnFile:        onFile,
+	})
+}
+
+// validatePackages runs package-scope validation once files are written.
+func validatePackages(ctx context.Context, im *scaffold.Importer, written []models.File) error {
+	if err := im.ValidatePackages(ctx, written); err != nil {
+		return fmt.Errorf("%w; run goscaffold undo to revert", err)
+	}
 	return nil
 }
 
+// touchedPaths are the paths a processed file changed, for git.
+func touchedPaths(f models.File) []string {
+	if f.Action == models.ActionRename {
+		return []string{f.Path, f.NewPath}
+	}
+	return []string{f.Path}
+}
+
+// describeAction phrases what importing f does, e.g. "Write main.go".
+func describeAction(f models.File) string {
+	switch f.Action {
+	case models.ActionDelete:
+		return "Delete " + f.Path
+	case models.ActionRename:
+		return "Rename " + describeTarget(f)
+	}
+	return "Write " + f.Path
+}
+
+func describeTarget(f models.File) string {
+	if f.Action == models.ActionRename {
+		return f.Path + " → " + f.NewPath
+	}
+	return f.Path
+}
+
 func runWatchMode(ctx context.Context) error {
-	if inputFile == "" {
-		return fmt.Errorf("--watch requires --input")
+	if useClipboard {
+		return runClipboardWatch(ctx)
 	}
+	if len(inputFiles) != 1 {
+		return fmt.Errorf("--watch requires a single --input, or --clipboard")
+	}
+	inputFile := inputFiles[0]
 
-	log.Info("Watching file", "path", inputFile)
+	debounce, err := time.ParseDuration(viper.GetString("watch.debounce"))
+	if err != nil || debounce < 0 {
+		return fmt.Errorf("invalid watch.debounce %q", viper.GetString("watch.debounce"))
+	}
 
-	watcher, err := fsnotify.NewWatcher()
+	target, err := newWatchTarget(inputFile)
 	if err != nil {
 		return err
 	}
-	defer watcher.Close()
+	initial := target.changed()
 
-	if err := watcher.Add(inputFile); err != nil {
-		return err
+	// fsnotify reports changes immediately; watch.poll falls back to checking
+	// the mtime every watch.interval for filesystems where it is unreliable,
+	// such as network mounts.
+	var (
+		events  <-chan fsnotify.Event
+		errs    <-chan error
+		poll    <-chan time.Time
+		rewatch = func() {}
+	)
+	if viper.GetBool("watch.poll") {
+		interval, err := watchInterval()
+		if err != nil {
+			return err
+		}
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		poll = ticker.C
+		log.Info("Watching", "path", inputFile, "poll", interval)
+	} else {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return err
+		}
+		defer watcher.Close()
+
+		if err := watcher.Add(inputFile); err != nil {
+			return err
+		}
+		events, errs = watcher.Events, watcher.Errors
+		log.Info("Watching", "path", inputFile)
+
+		// Editors that save by writing a new file and renaming it over the
+		// old one drop the watch along with the old file, so re-add it by
+		// path. Adding a path that is already watched is a no-op.
+		rewatch = func() {
+			if err := watcher.Add(inputFile); err != nil {
+				log.Debug("Re-adding watch failed", "path", inputFile, "error", err)
+			}
+		}
+	}
+
+	sess := newSession()
+
+	importChanged := func(paths []string) error {
+		for _, path := range paths {
+			content, err := os.ReadFile(path)
+			if err != nil {
+				log.Warn("Read failed", "path", path, "error", err)
+				continue
+			}
+			log.Info("File changed, reprocessing...", "path", path)
+			if err := watchImport(ctx, sess, string(content)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Only changes after startup count; the content present now is imported
+	// only with --watch-initial.
+	if watchInitial {
+		if err := importChanged(initial); err != nil {
+			return err
+		}
 	}
 
+	// Editors often save in several writes; import once the file has been
+	// quiet for the debounce period.
+	settled := time.NewTimer(debounce)
+	settled.Stop()
+	defer settled.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
 			return nil
-		case event, ok := <-watcher.Events:
+		case event, ok := <-events:
 			if !ok {
 				return nil
 			}
-			if event.Op&fsnotify.Write == fsnotify.Write {
-				log.Info("File changed, reprocessing...")
-				content, _ := os.ReadFile(inputFile)
-				files := parser.Parse(string(content))
-				if len(files) > 0 {
-					_ = runBatch(ctx, files)
-				}
+			if event.Op&(fsnotify.Rename|fsnotify.Remove) != 0 {
+				rewatch()
+			}
+			if event.Op&(fsnotify.Write|fsnotify.Create|fsnotify.Rename|fsnotify.Remove) != 0 {
+				settled.Reset(debounce)
+			}
+		case <-poll:
+			if err := importChanged(target.changed()); err != nil {
+				return err
 			}
-		case err, ok := <-watcher.Errors:
+		case <-settled.C:
+			rewatch()
+			if err := importChanged(target.changed()); err != nil {
+				return err
+			}
+		case err, ok := <-errs:
 			if !ok {
 				return nil
 			}
@@ -236,3 +1474,211 @@ func runWatchMode(ctx context.Context) error {
 		}
 	}
 }
+
+// runClipboardWatch polls the clipboard every watch.interval and imports
+// new content that contains a code fence.
+func runClipboardWatch(ctx context.Context) error {
+	interval, err := watchInterval()
+	if err != nil {
+		return err
+	}
+
+	log.Info("Watching clipboard", "interval", interval)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	sess := newSession()
+
+	// Content already on the clipboard when watching starts is only imported
+	// with --watch-initial.
+	last, _ := clipboard.Read()
+	if watchInitial && strings.Contains(last, "```") {
+		if err := watchImport(ctx, sess, last); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			content, err := clipboard.Read()
+			if err != nil {
+				log.Debug("Clipboard read failed", "error", err)
+				continue
+			}
+			if content == last {
+				continue
+			}
+			last = content
+
+			if !strings.Contains(content, "```") {
+				continue
+			}
+			log.Info("Clipboard changed, importing...")
+			if err := watchImport(ctx, sess, content); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// chatExts are the files imported when watching a directory.
+var chatExts = []string{".md", ".markdown"}
+
+// watchTarget tracks the mtime of the watched file, or of each chat file in
+// the watched directory, to tell which changed since the last scan.
+type watchTarget struct {
+	path string
+	dir  bool
+	mods map[string]time.Time
+}
+
+func newWatchTarget(path string) (*watchTarget, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	return &watchTarget{path: path, dir: info.IsDir(), mods: make(map[string]time.Time)}, nil
+}
+
+// files lists the watched file, or the chat files directly inside the
+// watched directory that --exclude does not match.
+func (t *watchTarget) files() []string {
+	if !t.dir {
+		return []string{t.path}
+	}
+
+	entries, err := os.ReadDir(t.path)
+	if err != nil {
+		log.Warn("Read dir failed", "path", t.path, "error", err)
+		return nil
+	}
+
+	var files []string
+	for _, e := range entries {
+		if e.IsDir() || !slices.Contains(chatExts, strings.ToLower(filepath.Ext(e.Name()))) {
+			continue
+		}
+		if matchAny(excludes, e.Name()) {
+			log.Debug("Excluded", "path", e.Name())
+			continue
+		}
+		files = append(files, filepath.Join(t.path, e.Name()))
+	}
+	return files
+}
+
+// changed returns the files that are new or whose mtime advanced since the
+// previous call.
+func (t *watchTarget) changed() []string {
+	var changed []string
+	for _, path := range t.files() {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if last, ok := t.mods[path]; ok && !info.ModTime().After(last) {
+			continue
+		}
+		t.mods[path] = info.ModTime()
+		changed = append(changed, path)
+	}
+	return changed
+}
+
+// watchInterval returns the polling interval, watch.interval.
+func watchInterval() (time.Duration, error) {
+	interval, err := time.ParseDuration(viper.GetString("watch.interval"))
+	if err != nil || interval <= 0 {
+		return 0, fmt.Errorf("invalid watch.interval %q", viper.GetString("watch.interval"))
+	}
+	return interval, nil
+}
+
+// watchImport imports content for one watch iteration. Processing errors
+// are logged so watching continues.
+func watchImport(ctx context.Context, sess *session, content string) error {
+	files, err := parseInput(content)
+	if err == nil {
+		files, err = dedupFiles(files)
+	}
+	if err != nil {
+		return err
+	}
+	if dedupRuns {
+		files = sess.filter(files)
+	}
+	if len(files) == 0 {
+		return nil
+	}
+	if err := checkBudget(files); err != nil {
+		log.Error("Import aborted", "error", err)
+		return nil
+	}
+
+	// Only files actually written count as imported; skipped, failed and
+	// unchanged ones are offered again next time.
+	var onFile func(models.File, bool)
+	if dedupRuns {
+		onFile = func(f models.File, written bool) {
+			if written {
+				sess.record(f)
+			}
+		}
+	}
+
+	emitter.Start(len(files))
+	err = runBatch(ctx, files, onFile)
+	emitter.End(err)
+	if err != nil {
+		log.Error("Import failed", "error", err)
+	}
+	return nil
+}
+
+// session remembers what has been written across watch iterations so
+// identical content is not re-imported.
+type session struct {
+	mu      sync.Mutex
+	written map[[sha256.Size]byte]struct{}
+	skipped int
+}
+
+func newSession() *session {
+	return &session{written: make(map[[sha256.Size]byte]struct{})}
+}
+
+func sessionKey(f models.File) [sha256.Size]byte {
+	return sha256.Sum256([]byte(f.Path + "\x00" + f.Code))
+}
+
+func (s *session) filter(files []models.File) []models.File {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var kept []models.File
+	skipped := 0
+	for _, f := range files {
+		if _, ok := s.written[sessionKey(f)]; ok {
+			skipped++
+			continue
+		}
+		kept = append(kept, f)
+	}
+
+	if skipped > 0 {
+		s.skipped += skipped
+		log.Info("Skipped files already imported this session", "count", skipped, "total", s.skipped)
+	}
+	return kept
+}
+
+func (s *session) record(f models.File) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.written[sessionKey(f)] = struct{}{}
+}
@@ -0,0 +1,228 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"goscaffold/internal/models"
+)
+
+// setupImport runs the test in an empty directory with a fresh config.
+func setupImport(t *testing.T) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+}
+
+// setFlag sets a package-level flag variable for the duration of the test.
+func setFlag[T any](t *testing.T, p *T, v T) {
+	t.Helper()
+	old := *p
+	*p = v
+	t.Cleanup(func() { *p = old })
+}
+
+func mustWrite(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func mustRead(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func block(path, code string) string {
+	return "```\n// path: " + path + "\n" + code + "```\n"
+}
+
+func TestWatchDedupRecordsOnlyWritten(t *testing.T) {
+	setupImport(t)
+	setFlag(t, &dedupRuns, true)
+	setFlag(t, &onConflict, "skip")
+
+	// a.txt exists with other content, so the first run skips it.
+	mustWrite(t, "a.txt", "mine\n")
+	sess := newSession()
+	ctx := context.Background()
+
+	if err := watchImport(ctx, sess, block("a.txt", "A\n")+block("b.txt", "B\n")); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustRead(t, "a.txt"); got != "mine\n" {
+		t.Fatalf("a.txt = %q after first run, want it skipped", got)
+	}
+
+	if err := os.Remove("a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if err := watchImport(ctx, sess, block("a.txt", "A\n")+block("b.txt", "B\n")+block("c.txt", "C\n")); err != nil {
+		t.Fatal(err)
+	}
+
+	// b.txt overlaps and was written, so it is the only file filtered out.
+	if sess.skipped != 1 {
+		t.Errorf("skipped %d files on the second run, want 1 (b.txt)", sess.skipped)
+	}
+	if got := mustRead(t, "a.txt"); got != "A\n" {
+		t.Errorf("a.txt = %q, want it imported on the second run", got)
+	}
+	if got := mustRead(t, "c.txt"); got != "C\n" {
+		t.Errorf("c.txt = %q", got)
+	}
+}
+
+func TestCommitEnabledPrecedence(t *testing.T) {
+	tests := []struct {
+		name   string
+		config bool
+		args   []string
+		want   bool
+	}{
+		{"config off", false, nil, false},
+		{"config on", true, nil, true},
+		{"flag on over config off", false, []string{"--git-commit"}, true},
+		{"flag off over config on", true, []string{"--git-commit=false"}, false},
+		{"push implies commit", false, []string{"--git-push"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viper.Reset()
+			t.Cleanup(viper.Reset)
+			viper.Set("git.auto_commit", tt.config)
+			setFlag(t, &gitCommit, false)
+			setFlag(t, &gitPush, false)
+
+			cmd := &cobra.Command{}
+			cmd.Flags().BoolVarP(&gitCommit, "git-commit", "g", false, "")
+			cmd.Flags().BoolVar(&gitPush, "git-push", false, "")
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := commitEnabled(cmd); got != tt.want {
+				t.Errorf("commitEnabled = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBatchOverwritesWithoutPromptByDefault(t *testing.T) {
+	setupImport(t)
+	for _, d := range defaults {
+		viper.SetDefault(d.key, d.value)
+	}
+	setFlag(t, &assumeYes, false)
+	setFlag(t, &quiet, false)
+	setFlag(t, &onConflict, "overwrite")
+
+	// Stdin is a terminal that would decline any prompt.
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { r.Close() })
+	if _, err := w.WriteString("n\n"); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	setFlag(t, &os.Stdin, r)
+	setFlag(t, &isTerminal, func(f *os.File) bool { return f == r })
+
+	mustWrite(t, "a.txt", "old\n")
+	if err := runBatch(context.Background(), []models.File{{Path: "a.txt", Code: "new\n"}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got := mustRead(t, "a.txt"); got != "new\n" {
+		t.Errorf("a.txt = %q, want it overwritten without asking", got)
+	}
+}
+
+func TestFailIfExistsAbortsBeforeWriting(t *testing.T) {
+	setupImport(t)
+	setFlag(t, &failIfExists, true)
+	mustWrite(t, "exists.go", "package mine\n")
+
+	files := []models.File{
+		{Path: "new.go", Code: "package x\n"},
+		{Path: "exists.go", Code: "package x\n"},
+	}
+	err := importFiles(context.Background(), files)
+	if err == nil || !strings.Contains(err.Error(), "exists.go") {
+		t.Fatalf("importFiles error = %v, want one naming exists.go", err)
+	}
+	if _, err := os.Stat("new.go"); !os.IsNotExist(err) {
+		t.Error("new.go was written despite the pre-flight failure")
+	}
+	if got := mustRead(t, "exists.go"); got != "package mine\n" {
+		t.Errorf("exists.go = %q, want it untouched", got)
+	}
+
+	// Deletes do not count as targets.
+	err = checkNoneExist([]models.File{{Path: "exists.go", Action: models.ActionDelete}})
+	if err != nil {
+		t.Errorf("checkNoneExist with a delete = %v", err)
+	}
+}
+
+func TestDedupFiles(t *testing.T) {
+	files := []models.File{
+		{Path: "main.go", Code: "first\n", Source: "a.md", StartLine: 3},
+		{Path: "util.go", Code: "util\n", StartLine: 8},
+		{Path: "main.go", Code: "second\n", Source: "b.md", StartLine: 12},
+		{Path: "main.go", Code: "third\n", Source: "b.md", StartLine: 20},
+	}
+	codes := func(files []models.File) []string {
+		var out []string
+		for _, f := range files {
+			out = append(out, f.Path+"="+strings.TrimSpace(f.Code))
+		}
+		return out
+	}
+
+	tests := []struct {
+		policy string
+		want   []string
+	}{
+		{"last", []string{"main.go=third", "util.go=util"}},
+		{"first", []string{"main.go=first", "util.go=util"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			setFlag(t, &dupPolicy, tt.policy)
+
+			got, err := dedupFiles(files)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(codes(got), tt.want) {
+				t.Errorf("dedupFiles = %v, want %v", codes(got), tt.want)
+			}
+		})
+	}
+
+	t.Run("error", func(t *testing.T) {
+		setFlag(t, &dupPolicy, "error")
+
+		_, err := dedupFiles(files)
+		if err == nil || !strings.Contains(err.Error(), "a.md:3") || !strings.Contains(err.Error(), "b.md:12") {
+			t.Errorf("dedupFiles error = %v, want both origins of main.go", err)
+		}
+		if _, err := dedupFiles(files[:2]); err != nil {
+			t.Errorf("dedupFiles without duplicates = %v", err)
+		}
+	})
+}
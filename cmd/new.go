@@ -1,21 +1,68 @@
 package cmd
 
 import (
+	"context"
+	"embed"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strings"
 	"text/template"
+	"time"
 
 	"github.com/charmbracelet/log"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+
+	"goscaffold/internal/models"
+	"goscaffold/pkg/config"
+	"goscaffold/pkg/git"
+	dirtemplate "goscaffold/pkg/template"
 )
 
+//go:embed all:templates/default templates/ci
+var templateFS embed.FS
+
+const templateSuffix = ".tmpl"
+
+var ciWorkflows = map[string]string{
+	"github": ".github/workflows/ci.yml",
+	"gitlab": ".gitlab-ci.yml",
+}
+
+// knownModules maps --modules shorthands to module paths. Anything else is
+// used as a module path verbatim.
+var knownModules = map[string]string{
+	"gin":     "github.com/gin-gonic/gin",
+	"echo":    "github.com/labstack/echo/v4",
+	"chi":     "github.com/go-chi/chi/v5",
+	"fiber":   "github.com/gofiber/fiber/v2",
+	"zerolog": "github.com/rs/zerolog",
+	"zap":     "go.uber.org/zap",
+	"logrus":  "github.com/sirupsen/logrus",
+	"cobra":   "github.com/spf13/cobra",
+	"viper":   "github.com/spf13/viper",
+	"testify": "github.com/stretchr/testify",
+	"uuid":    "github.com/google/uuid",
+	"gorm":    "gorm.io/gorm",
+	"sqlx":    "github.com/jmoiron/sqlx",
+	"pgx":     "github.com/jackc/pgx/v5",
+}
+
 var (
-	templateName string
-	modules      []string
-	overwrite    bool
-	initGit      bool
+	templateName      string
+	modules           []string
+	overwrite         bool
+	initGit           bool
+	templateOverrides string
+	ciProvider        string
+	goVersion         string
+	modulePath        string
+	offline           bool
+	noTidy            bool
 )
 
 var newCmd = &cobra.Command{
@@ -32,6 +79,12 @@ func init() {
 	newCmd.Flags().StringSliceVarP(&modules, "modules", "m", []string{}, "Go modules to init")
 	newCmd.Flags().BoolVar(&overwrite, "overwrite", false, "Overwrite existing")
 	newCmd.Flags().BoolVar(&initGit, "git", false, "Initialize git repo")
+	newCmd.Flags().StringVar(&ciProvider, "ci", "none", "CI workflow to generate (github|gitlab|none)")
+	newCmd.Flags().BoolVar(&offline, "offline", false, "Skip network operations such as go get")
+	newCmd.Flags().BoolVar(&noTidy, "no-tidy", false, "Skip go mod tidy and gofmt after generation")
+	newCmd.Flags().StringVar(&modulePath, "module", "", "Go module path (default: project name)")
+	newCmd.Flags().StringVar(&goVersion, "go-version", "1.22", "Go version for go.mod and CI")
+	newCmd.Flags().StringVar(&templateOverrides, "template-overrides", "", "Directory whose files shadow embedded templates (default: new.template_overrides)")
 
 	rootCmd.AddCommand(newCmd)
 }
@@ -41,13 +94,169 @@ func runNew(cmd *cobra.Command, args []string) error {
 	name := args[0]
 	path := filepath.Join(".", name)
 
+	module := modulePath
+	if module == "" {
+		module = name
+	}
+	if err := checkModulePath(module); err != nil {
+		return err
+	}
+
+	if _, ok := ciWorkflows[ciProvider]; !ok && ciProvider != "none" {
+		return fmt.Errorf("invalid --ci %q (want github, gitlab or none)", ciProvider)
+	}
+
 	if _, err := os.Stat(path); err == nil && !overwrite {
 		return fmt.Errorf("directory %s already exists (use --overwrite)", name)
 	}
 
-	log.Info("Creating project", "name", name, "path", path)
+	tmpl, err := findTemplate(templateName)
+	if err != nil {
+		return err
+	}
+
+	log.Info("Creating project", "name", name, "module", module, "path", path)
+
+	overrides := templateOverrides
+	if overrides == "" {
+		cfg, err := config.Load()
+		if err != nil {
+			return fmt.Errorf("load config: %w", err)
+		}
+		overrides = cfg.New.TemplateOverrides
+	}
+
+	data := map[string]string{
+		"Name":      name,
+		"Module":    module,
+		"GoVersion": goVersion,
+		"Branch":    viper.GetString("git.default_branch"),
+		"Date":      time.Now().Format(time.DateOnly),
+	}
+
+	if tmpl != nil && tmpl.Dir != "" {
+		log.Info("Using template", "name", tmpl.Name, "dir", tmpl.Dir)
+		if err := os.MkdirAll(path, 0755); err != nil {
+			return fmt.Errorf("mkdir %s: %w", path, err)
+		}
+		if err := dirtemplate.Render(tmpl.Dir, path, data); err != nil {
+			return err
+		}
+	} else if tmpl != nil {
+		log.Info("Using template", "name", tmpl.Name)
+		if err := os.MkdirAll(path, 0755); err != nil {
+			return fmt.Errorf("mkdir %s: %w", path, err)
+		}
+		if err := createStructure(path, "", tmpl.Structure, overrides, data); err != nil {
+			return err
+		}
+	} else {
+		if err := createDefaultLayout(path); err != nil {
+			return err
+		}
+		if err := renderTemplates(path, overrides, data); err != nil {
+			return err
+		}
+	}
+
+	if ciProvider != "none" {
+		if err := renderCI(path, ciProvider, data); err != nil {
+			return err
+		}
+	}
+
+	if len(modules) > 0 {
+		addModules(cmd.Context(), path, modules)
+	}
+
+	if !noTidy {
+		tidy(cmd.Context(), path)
+	}
+
+	// Init git
+	if initGit || viper.GetBool("git.auto_init") {
+		if err := git.InitRepo(path, viper.GetString("git.default_branch")); err != nil {
+			log.Warn("Git init failed", "error", err)
+		}
+	}
+
+	log.Info("✨ Project created", "name", name, "path", path)
+	return nil
+}
+
+// addModules runs go get for each requested module in the project at path.
+// Failures are logged so the rest of the project is still created.
+func addModules(ctx context.Context, path string, mods []string) {
+	for _, m := range mods {
+		mod := strings.TrimSpace(m)
+		if alias, ok := knownModules[mod]; ok {
+			mod = alias
+		}
+		if mod == "" {
+			continue
+		}
+
+		if offline {
+			log.Info("Offline, skipping go get", "module", mod)
+			continue
+		}
+
+		log.Info("Adding module", "module", mod)
+		c := exec.CommandContext(ctx, "go", "get", mod)
+		c.Dir = path
+		if out, err := c.CombinedOutput(); err != nil {
+			log.Warn("go get failed", "module", mod, "error", err, "output", strings.TrimSpace(string(out)))
+		}
+	}
+}
+
+// tidy runs go mod tidy (unless offline) and gofmt -w over the project at
+// path. Failures are logged and leave the generated files in place.
+func tidy(ctx context.Context, path string) {
+	if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil && !offline {
+		c := exec.CommandContext(ctx, "go", "mod", "tidy")
+		c.Dir = path
+		if out, err := c.CombinedOutput(); err != nil {
+			log.Warn("go mod tidy failed", "error", err, "output", strings.TrimSpace(string(out)))
+		}
+	}
+
+	c := exec.CommandContext(ctx, "gofmt", "-w", ".")
+	c.Dir = path
+	if out, err := c.CombinedOutput(); err != nil {
+		log.Warn("gofmt failed", "error", err, "output", strings.TrimSpace(string(out)))
+	}
+}
 
-	// Create structure
+// checkModulePath applies the basic go.mod module path rules: slash-separated
+// non-empty elements of letters, digits and ".-_~", not starting or ending
+// with a dot.
+func checkModulePath(path string) error {
+	if path == "" {
+		return fmt.Errorf("invalid module path: empty")
+	}
+	for _, elem := range strings.Split(path, "/") {
+		if elem == "" {
+			return fmt.Errorf("invalid module path %q: empty path element", path)
+		}
+		if strings.HasPrefix(elem, ".") || strings.HasSuffix(elem, ".") {
+			return fmt.Errorf("invalid module path %q: element %q starts or ends with a dot", path, elem)
+		}
+		for _, r := range elem {
+			if !modulePathRune(r) {
+				return fmt.Errorf("invalid module path %q: invalid character %q", path, r)
+			}
+		}
+	}
+	return nil
+}
+
+func modulePathRune(r rune) bool {
+	return 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9' ||
+		r == '.' || r == '-' || r == '_' || r == '~'
+}
+
+func createDefaultLayout(path string) error {
 	dirs := []string{
 		path,
 		filepath.Join(path, "cmd"),
@@ -63,47 +272,155 @@ func runNew(cmd *cobra.Command, args []string) error {
 		}
 		log.Debug("Created directory", "path", dir)
 	}
+	return nil
+}
 
-	// Create main.go
-	mainTmpl := `package main
+// findTemplate returns the configured template called name, or else the
+// directory of that name under new.templates_dir. The built-in "default"
+// layout is used when neither claims that name, in which case findTemplate
+// returns nil.
+func findTemplate(name string) (*config.Template, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
 
-import (
-	"fmt"
-)
+	var names []string
+	for i := range cfg.Templates {
+		if cfg.Templates[i].Name == name {
+			return &cfg.Templates[i], nil
+		}
+		names = append(names, cfg.Templates[i].Name)
+	}
 
-func main() {
-	fmt.Println("Hello from {{.Name}}!")
+	dirs, err := dirtemplate.List(cfg.New.TemplatesDir)
+	if err != nil {
+		log.Warn("Reading templates directory failed", "dir", cfg.New.TemplatesDir, "error", err)
+	}
+	for _, d := range dirs {
+		if d == name {
+			return &config.Template{Name: name, Dir: filepath.Join(cfg.New.TemplatesDir, d)}, nil
+		}
+		names = append(names, d)
+	}
+
+	if name == "default" {
+		return nil, nil
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("template %q not found: no templates are configured", name)
+	}
+	return nil, fmt.Errorf("template %q not found (available: default, %s)", name, strings.Join(names, ", "))
 }
-`
-	if err := writeTemplate(filepath.Join(path, "cmd", "main.go"), mainTmpl, map[string]string{"Name": name}); err != nil {
-		return err
+
+// createStructure materializes a config.Template structure under root:
+//
+//   - a map (or null) value is a directory, whose map entries are created
+//     inside it;
+//   - a string value is a file whose contents are rendered with text/template;
+//   - a string starting with "@" instead references a built-in template file,
+//     e.g. "@go.mod" or "@cmd/main.go", honoring --template-overrides.
+//
+// Names may contain slashes. Note that viper lower-cases map keys.
+func createStructure(root, rel string, structure map[string]interface{}, overrides string, data interface{}) error {
+	for name, node := range structure {
+		childRel := filepath.Join(rel, filepath.FromSlash(name))
+		if err := (models.File{Path: childRel}).Validate(); err != nil {
+			return fmt.Errorf("template structure: %w", err)
+		}
+		dst := filepath.Join(root, childRel)
+
+		switch v := node.(type) {
+		case nil:
+			if err := os.MkdirAll(dst, 0755); err != nil {
+				return fmt.Errorf("mkdir %s: %w", dst, err)
+			}
+		case map[string]interface{}:
+			if err := os.MkdirAll(dst, 0755); err != nil {
+				return fmt.Errorf("mkdir %s: %w", dst, err)
+			}
+			if err := createStructure(root, childRel, v, overrides, data); err != nil {
+				return err
+			}
+		case string:
+			content := v
+			if ref, ok := strings.CutPrefix(v, "@"); ok {
+				var err error
+				if content, err = loadTemplate(ref, overrides); err != nil {
+					return fmt.Errorf("%s: %w", childRel, err)
+				}
+			}
+			if err := renderFile(root, childRel, content, data); err != nil {
+				return err
+			}
+		default:
+			return fmt.Errorf("template structure: unsupported value %T at %s", node, childRel)
+		}
 	}
+	return nil
+}
 
-	// Create go.mod
-	modContent := fmt.Sprintf(`module %s
+// loadTemplate returns the built-in template for rel (a path without .tmpl),
+// or the file at the same relative path under overrides if there is one.
+func loadTemplate(rel, overrides string) (string, error) {
+	if overrides != "" {
+		override := filepath.Join(overrides, filepath.FromSlash(rel))
+		b, err := os.ReadFile(override)
+		switch {
+		case err == nil:
+			log.Debug("Using template override", "file", rel, "override", override)
+			return string(b), nil
+		case !errors.Is(err, fs.ErrNotExist):
+			return "", fmt.Errorf("read override %s: %w", override, err)
+		}
+	}
 
-go 1.22
-`, name)
+	b, err := templateFS.ReadFile("templates/default/" + filepath.ToSlash(rel) + templateSuffix)
+	if err != nil {
+		return "", fmt.Errorf("unknown built-in template %q", rel)
+	}
+	return string(b), nil
+}
 
-	if err := os.WriteFile(filepath.Join(path, "go.mod"), []byte(modContent), 0644); err != nil {
+// renderTemplates renders every embedded template into root. A file at the
+// same relative path (without .tmpl) under overrides replaces the embedded one.
+func renderTemplates(root, overrides string, data interface{}) error {
+	tmplFS, err := fs.Sub(templateFS, "templates/default")
+	if err != nil {
 		return err
 	}
 
-	// Create .gitignore
-	gitignore := `.env
-*.log
-.goscaffold-backup/
-`
-	os.WriteFile(filepath.Join(path, ".gitignore"), []byte(gitignore), 0644)
+	return fs.WalkDir(tmplFS, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
 
-	// Init git
-	if initGit || viper.GetBool("git.auto_init") {
-		if err := initGitRepo(path); err != nil {
-			log.Warn("Git init failed", "error", err)
+		rel := strings.TrimSuffix(name, templateSuffix)
+		content, err := loadTemplate(rel, overrides)
+		if err != nil {
+			return err
 		}
+		return renderFile(root, filepath.FromSlash(rel), content, data)
+	})
+}
+
+func renderCI(root, provider string, data interface{}) error {
+	content, err := templateFS.ReadFile("templates/ci/" + provider + ".yml" + templateSuffix)
+	if err != nil {
+		return err
 	}
+	return renderFile(root, filepath.FromSlash(ciWorkflows[provider]), string(content), data)
+}
 
-	log.Info("✨ Project created", "name", name, "path", path)
+func renderFile(root, rel, content string, data interface{}) error {
+	dst := filepath.Join(root, rel)
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("mkdir %s: %w", filepath.Dir(dst), err)
+	}
+	if err := writeTemplate(dst, content, data); err != nil {
+		return fmt.Errorf("render %s: %w", rel, err)
+	}
+	log.Debug("Created file", "path", dst)
 	return nil
 }
 
@@ -121,8 +438,3 @@ func writeTemplate(path, tmpl string, data interface{}) error {
 
 	return t.Execute(f, data)
 }
-
-func initGitRepo(path string) error {
-	// Simplified git init
-	return nil
-}
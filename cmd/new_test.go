@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testData = map[string]string{
+	"Name":      "demo",
+	"Module":    "example.com/demo",
+	"GoVersion": "1.23",
+	"Branch":    "main",
+	"Date":      "2024-01-02",
+}
+
+func TestRenderTemplatesOverride(t *testing.T) {
+	overrides := t.TempDir()
+	mustWrite(t, filepath.Join(overrides, ".gitignore"), "custom-ignore\n")
+
+	root := t.TempDir()
+	if err := renderTemplates(root, overrides, testData); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := mustRead(t, filepath.Join(root, ".gitignore")); got != "custom-ignore\n" {
+		t.Errorf(".gitignore = %q, want the override", got)
+	}
+	if got := mustRead(t, filepath.Join(root, "go.mod")); !strings.HasPrefix(got, "module example.com/demo\n") {
+		t.Errorf("go.mod = %q, want the default template", got)
+	}
+	for _, rel := range []string{"cmd/main.go", "internal/app/app.go"} {
+		if _, err := os.Stat(filepath.Join(root, rel)); err != nil {
+			t.Errorf("default file %s not rendered: %v", rel, err)
+		}
+	}
+}
+
+func TestRenderCIUsesGoVersion(t *testing.T) {
+	for provider, rel := range ciWorkflows {
+		t.Run(provider, func(t *testing.T) {
+			root := t.TempDir()
+			if err := renderCI(root, provider, testData); err != nil {
+				t.Fatal(err)
+			}
+
+			got := mustRead(t, filepath.Join(root, filepath.FromSlash(rel)))
+			if !strings.Contains(got, "1.23") {
+				t.Errorf("%s does not reference Go 1.23:\n%s", rel, got)
+			}
+			if !strings.Contains(got, "go test ./...") {
+				t.Errorf("%s does not run the tests:\n%s", rel, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"goscaffold/pkg/parser"
+)
+
+var promptFormat string
+
+var promptCmd = &cobra.Command{
+	Use:   "prompt [flags]",
+	Short: "Print instructions that make AI output importable",
+	Long: `Print a block to paste into an AI chat so that its answers use the file
+format goscaffold import understands.`,
+	Example: `  goscaffold prompt
+  goscaffold prompt --format yaml | pbcopy`,
+	Args: cobra.NoArgs,
+	RunE: runPrompt,
+}
+
+func init() {
+	promptCmd.Flags().StringVar(&promptFormat, "format", "markdown", "Output style to ask for: markdown or yaml")
+
+	rootCmd.AddCommand(promptCmd)
+}
+
+func runPrompt(cmd *cobra.Command, args []string) error {
+	format, err := parser.ParseFormat(promptFormat)
+	if err != nil {
+		return err
+	}
+
+	prompt, err := parser.Prompt(format)
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(cmd.OutOrStdout(), prompt)
+	return nil
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+)
+
+var (
+	restoreFile   string
+	restoreAll    bool
+	restoreDryRun bool
+)
+
+var restoreCmd = &cobra.Command{
+	Use:   "restore [flags]",
+	Short: "Restore files from backups",
+	Long:  `List backups under backup.path (default .goscaffold-backup) and move them back to their original locations.`,
+	Example: `  goscaffold restore
+  goscaffold restore --file cmd/main.go
+  goscaffold restore --all --dry-run`,
+	RunE: runRestore,
+}
+
+func init() {
+	restoreCmd.Flags().StringVarP(&restoreFile, "file", "f", "", "Restore the latest backup of this path")
+	restoreCmd.Flags().BoolVarP(&restoreAll, "all", "a", false, "Restore the latest backup of every file")
+	restoreCmd.Flags().BoolVarP(&restoreDryRun, "dry-run", "d", false, "Preview without restoring")
+	restoreCmd.MarkFlagsMutuallyExclusive("file", "all")
+
+	rootCmd.AddCommand(restoreCmd)
+}
+
+func runRestore(cmd *cobra.Command, args []string) error {
+	bm, err := newBackupManager()
+	if err != nil {
+		return err
+	}
+
+	entries, err := bm.List()
+	if err != nil {
+		return fmt.Errorf("list backups: %w", err)
+	}
+	if len(entries) == 0 {
+		log.Info("No backups found")
+		return nil
+	}
+
+	// Entries are newest first, so the first hit per path is the one restored.
+	var targets []string
+	switch {
+	case restoreFile != "":
+		// Latest resolves the path the way backups were keyed, which is
+		// absolute when backup.path is.
+		e, ok, err := bm.Latest(restoreFile)
+		if err != nil {
+			return fmt.Errorf("list backups: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("no backup found for %s", restoreFile)
+		}
+		targets = append(targets, e.Original)
+	default:
+		seen := make(map[string]bool)
+		for _, e := range entries {
+			if seen[e.Original] {
+				continue
+			}
+			seen[e.Original] = true
+
+			if restoreAll {
+				targets = append(targets, e.Original)
+			} else {
+				log.Info(fmt.Sprintf("%s (%s)", e.Original, e.Time.Format("2006-01-02 15:04:05")), "backup", e.Path)
+			}
+		}
+		if !restoreAll {
+			return nil
+		}
+	}
+
+	for _, path := range targets {
+		if restoreDryRun {
+			log.Info(fmt.Sprintf("Would restore: %s", path))
+			continue
+		}
+		if err := bm.Restore(path); err != nil {
+			return err
+		}
+		log.Info("Restored", "path", path)
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestRestoreFileAbsoluteBackupPath(t *testing.T) {
+	t.Chdir(t.TempDir())
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.Set("backup.path", filepath.Join(t.TempDir(), "backups"))
+
+	if err := os.WriteFile("main.go", []byte("original"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	bm, err := newBackupManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := bm.Backup("main.go"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("main.go", []byte("imported"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	restoreFile, restoreAll, restoreDryRun = "./main.go", false, false
+	t.Cleanup(func() { restoreFile = "" })
+	if err := runRestore(restoreCmd, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile("main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "original" {
+		t.Errorf("main.go = %q, want the backed-up content", data)
+	}
+}
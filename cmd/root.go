@@ -3,20 +3,31 @@ package cmd
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/charmbracelet/log"
+	"github.com/muesli/termenv"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
+
+	"goscaffold/pkg/backup"
+	"goscaffold/pkg/config"
+	"goscaffold/pkg/formatter"
+	"goscaffold/pkg/ui"
+	"goscaffold/pkg/validator"
 )
 
 var (
 	cfgFile string
 	debug   bool
 	trace   bool
+	noColor bool
+	quiet   bool
 	version = "1.0.0"
 )
 
@@ -24,25 +35,39 @@ var rootCmd = &cobra.Command{
 	Use:     "goscaffold",
 	Short:   "Advanced Go project scaffolding with AI integration",
 	Version: version,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		initLogging()
-		initConfig()
+		return initConfig(cmd)
 	},
 }
 
+const shutdownTimeout = 10 * time.Second
+
 func Execute() error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// Graceful shutdown
+	// Graceful shutdown: the first signal cancels ctx so commands stop
+	// starting new work and return once in-flight writes finish. A second
+	// signal, or commands not finishing within shutdownTimeout, forces exit.
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(sigChan)
 	go func() {
-		<-sigChan
-		log.Info("Shutting down gracefully...")
+		select {
+		case <-sigChan:
+		case <-ctx.Done():
+			return
+		}
+		log.Info("Shutting down gracefully... (press Ctrl+C again to force)")
 		cancel()
-		time.Sleep(1 * time.Second)
-		os.Exit(0)
+
+		select {
+		case <-sigChan:
+		case <-time.After(shutdownTimeout):
+		}
+		log.Error("Forced shutdown")
+		os.Exit(130)
 	}()
 
 	return rootCmd.ExecuteContext(ctx)
@@ -52,6 +77,8 @@ func init() {
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default: $HOME/.goscaffold.yaml)")
 	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "enable debug logging")
 	rootCmd.PersistentFlags().BoolVar(&trace, "trace", false, "enable trace logging")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "only log errors and print a one-line summary (--debug wins)")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "disable colored output (also NO_COLOR)")
 }
 
 func initLogging() {
@@ -62,15 +89,73 @@ func initLogging() {
 	if trace {
 		level = log.DebugLevel // charmbracelet/log does not have TraceLevel
 	}
+	if quiet && level == log.InfoLevel {
+		level = log.ErrorLevel
+	}
 
 	log.SetLevel(level)
 	log.SetReportTimestamp(true)
 	log.SetTimeFormat(time.RFC3339)
 	log.SetPrefix("goscaffold")
+
+	useColor = !noColor && os.Getenv("NO_COLOR") == "" && isTerminal(os.Stdout)
+	if !useColor {
+		log.SetColorProfile(termenv.Ascii)
+	}
+	ui.SetColor(useColor)
+}
+
+// useColor reports whether output may contain ANSI colors. It is set by
+// initLogging.
+var useColor bool
+
+// isTerminal reports whether f is a terminal. It is a variable so tests can
+// pretend stdin is one.
+var isTerminal = func(f *os.File) bool {
+	stat, err := f.Stat()
+	return err == nil && stat.Mode()&os.ModeCharDevice != 0
+}
+
+// defaults are the built-in config values, in the order config init writes
+// them.
+var defaults = []struct {
+	key   string
+	value interface{}
+	help  string
+}{
+	{"backup.enabled", true, "Back up files before overwriting them"},
+	{"backup.retention", "7d", "Delete backups older than this (h, d or w suffix; 0 keeps them)"},
+	{"backup.keep", 5, "Backups kept per file (0 keeps all)"},
+	{"backup.path", ".goscaffold-backup", "Backup directory, relative to the working directory or absolute"},
+	{"git.auto_commit", false, "Commit imported files"},
+	{"git.default_branch", "main", "Branch for new repositories"},
+	{"git.remote", "origin", "Remote used by import --git-push"},
+	{"git.commit_template", defaultCommitMessage, "Import commit message; {{.FileCount}}, {{.Languages}}, {{.Date}} and the stats counts are available"},
+	{"watch.interval", "5s", "Polling interval for import --watch --clipboard and watch.poll"},
+	{"watch.poll", false, "Poll the watched file's mtime instead of using filesystem events"},
+	{"watch.debounce", "500ms", "Quiet period after a change before import --watch reads the file"},
+	{"import.concurrency", 4, "Files processed in parallel"},
+	{"new.templates_dir", "$HOME/.goscaffold/templates", "Directory whose subdirectories are file-based templates for new --template"},
+	{"ui.confirm_create", false, "Ask before each overwrite in batch imports on a terminal (skip with --yes)"},
+	{"ui.theme", "dark", "Preview highlighting: dark, light or none"},
+	{"parser.unnamed_strategy", "sequential", "Naming for blocks without a path: sequential, hash or identifier"},
+}
+
+// newBackupManager returns a backup manager for the backup settings of the
+// loaded config.
+func newBackupManager() (*backup.Manager, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	return backup.NewManager(cfg.Backup.Path, cfg.Backup.Retention, cfg.Backup.Keep), nil
 }
 
-func initConfig() {
+// initConfig reads the config and applies it. Invalid config fails every
+// command except doctor and config, which are how it gets fixed.
+func initConfig(cmd *cobra.Command) error {
 	viper.SetEnvPrefix("GOSCAFFOLD")
+	viper.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 	viper.AutomaticEnv()
 
 	if cfgFile != "" {
@@ -85,13 +170,9 @@ func initConfig() {
 		}
 	}
 
-	// Defaults
-	viper.SetDefault("backup.enabled", true)
-	viper.SetDefault("backup.retention", "7d")
-	viper.SetDefault("git.auto_commit", false)
-	viper.SetDefault("git.default_branch", "main")
-	viper.SetDefault("watch.interval", "5s")
-	viper.SetDefault("ui.confirm_create", true)
+	for _, d := range defaults {
+		viper.SetDefault(d.key, d.value)
+	}
 
 	if err := viper.ReadInConfig(); err != nil {
 		var configFileNotFoundError viper.ConfigFileNotFoundError
@@ -99,4 +180,39 @@ func initConfig() {
 			log.Warn("Error reading config", "error", err)
 		}
 	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Warn("Error loading config", "error", err)
+		return nil
+	}
+	if err := cfg.Validate(); err != nil {
+		if !fixesConfig(cmd) {
+			// The command line is fine; its usage would bury the error.
+			cmd.SilenceUsage = true
+			return fmt.Errorf("invalid config %s: %w", configSource(), err)
+		}
+		log.Error("Invalid config", "file", viper.ConfigFileUsed(), "error", err)
+	}
+	if err := validator.Configure(cfg.Validators); err != nil {
+		log.Warn("Error configuring validators", "error", err)
+	}
+	if err := formatter.Configure(cfg.Formatters); err != nil {
+		log.Warn("Error configuring formatters", "error", err)
+	}
+	if err := ui.SetTheme(cfg.UI.Theme); err != nil {
+		log.Warn("Error configuring theme", "error", err)
+	}
+	return nil
+}
+
+// fixesConfig reports whether cmd is doctor or a config subcommand, which
+// run with an invalid config so it can be diagnosed and rewritten.
+func fixesConfig(cmd *cobra.Command) bool {
+	for c := cmd; c != nil; c = c.Parent() {
+		if c == doctorCmd || c == configCmd {
+			return true
+		}
+	}
+	return false
 }
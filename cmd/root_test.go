@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func TestNewBackupManagerExpandsEnv(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("GS_TEST_BACKUPS", dir)
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	viper.Set("backup.path", "$GS_TEST_BACKUPS/goscaffold")
+
+	bm, err := newBackupManager()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(dir, "goscaffold"); bm.Dir() != want {
+		t.Errorf("Dir() = %s, want %s", bm.Dir(), want)
+	}
+}
+
+func TestInvalidConfigFailsCommands(t *testing.T) {
+	setupImport(t)
+	mustWrite(t, "bad.yaml", "backup:\n  retention: soon\n")
+	setFlag(t, &cfgFile, "bad.yaml")
+
+	if err := initConfig(importCmd); err == nil || !strings.Contains(err.Error(), "backup.retention") {
+		t.Errorf("import: initConfig = %v, want the backup.retention error", err)
+	}
+	for _, cmd := range []*cobra.Command{doctorCmd, configShowCmd} {
+		if err := initConfig(cmd); err != nil {
+			t.Errorf("%s: initConfig = %v, want it to run so the config can be fixed", cmd.CommandPath(), err)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"goscaffold/pkg/config"
+	dirtemplate "goscaffold/pkg/template"
+)
+
+var templatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "Inspect project templates for new",
+}
+
+var templatesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List available project templates",
+	Args:  cobra.NoArgs,
+	RunE:  runTemplatesList,
+}
+
+var templatesShowCmd = &cobra.Command{
+	Use:     "show [name]",
+	Short:   "Show the directory tree a template creates",
+	Args:    cobra.ExactArgs(1),
+	Example: `  goscaffold templates show default`,
+	RunE:    runTemplatesShow,
+}
+
+const templatesHelp = `No templates are configured. Define them in ~/.goscaffold.yaml, e.g.:
+
+templates:
+  - name: api
+    description: HTTP service
+    structure:
+      cmd:
+        main.go: "@cmd/main.go"
+      internal: {}
+      go.mod: "@go.mod"
+
+Or put a directory of files under new.templates_dir (~/.goscaffold/templates),
+e.g. ~/.goscaffold/templates/api; files ending in .tmpl are rendered with
+{{.Name}}, {{.Module}} and {{.Date}}.`
+
+func init() {
+	templatesCmd.AddCommand(templatesListCmd, templatesShowCmd)
+	rootCmd.AddCommand(templatesCmd)
+}
+
+func runTemplatesList(cmd *cobra.Command, args []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+
+	fmt.Printf("%-16s %s\n", "default", "Built-in Go project layout")
+	for _, t := range cfg.Templates {
+		fmt.Printf("%-16s %s\n", t.Name, t.Description)
+	}
+
+	dirs, err := dirtemplate.List(cfg.New.TemplatesDir)
+	if err != nil {
+		return fmt.Errorf("list %s: %w", cfg.New.TemplatesDir, err)
+	}
+	for _, d := range dirs {
+		fmt.Printf("%-16s %s\n", d, "Files in "+filepath.Join(cfg.New.TemplatesDir, d))
+	}
+
+	if len(cfg.Templates) == 0 && len(dirs) == 0 {
+		fmt.Println()
+		fmt.Println(templatesHelp)
+	}
+	return nil
+}
+
+func runTemplatesShow(cmd *cobra.Command, args []string) error {
+	tmpl, err := findTemplate(args[0])
+	if err != nil {
+		return err
+	}
+
+	structure := defaultStructure()
+	name := "default"
+	if tmpl != nil {
+		structure, name = tmpl.Structure, tmpl.Name
+	}
+	if tmpl != nil && tmpl.Dir != "" {
+		paths, err := dirtemplate.Files(tmpl.Dir)
+		if err != nil {
+			return fmt.Errorf("read template %s: %w", tmpl.Dir, err)
+		}
+		structure = map[string]interface{}{}
+		for _, p := range paths {
+			addPath(structure, strings.Split(p, "/"))
+		}
+	}
+
+	fmt.Println(name + "/")
+	printTree(structure, "")
+	return nil
+}
+
+// defaultStructure describes the built-in layout in the same shape as a
+// config.Template structure.
+func defaultStructure() map[string]interface{} {
+	root := map[string]interface{}{}
+	for _, dir := range []string{"cmd", "internal", "pkg", "api", "configs"} {
+		root[dir] = map[string]interface{}{}
+	}
+
+	tmplFS, err := fs.Sub(templateFS, "templates/default")
+	if err != nil {
+		return root
+	}
+	_ = fs.WalkDir(tmplFS, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		addPath(root, strings.Split(strings.TrimSuffix(name, templateSuffix), "/"))
+		return nil
+	})
+	return root
+}
+
+// addPath adds the file at parts, creating its directories, to structure.
+func addPath(structure map[string]interface{}, parts []string) {
+	node := structure
+	for _, dir := range parts[:len(parts)-1] {
+		child, ok := node[dir].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{}
+			node[dir] = child
+		}
+		node = child
+	}
+	node[parts[len(parts)-1]] = "@" + strings.Join(parts, "/")
+}
+
+func printTree(structure map[string]interface{}, indent string) {
+	names := make([]string, 0, len(structure))
+	for name := range structure {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for i, name := range names {
+		branch, next := "├── ", "│   "
+		if i == len(names)-1 {
+			branch, next = "└── ", "    "
+		}
+
+		switch v := structure[name].(type) {
+		case map[string]interface{}:
+			fmt.Println(indent + branch + name + "/")
+			printTree(v, indent+next)
+		case nil:
+			fmt.Println(indent + branch + name + "/")
+		default:
+			fmt.Println(indent + branch + name)
+		}
+	}
+}
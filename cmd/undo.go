@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+
+	"goscaffold/pkg/backup"
+)
+
+var (
+	undoSession string
+	undoList    bool
+)
+
+var undoCmd = &cobra.Command{
+	Use:   "undo [flags]",
+	Short: "Revert an import session",
+	Long: `Delete the files created by an import and restore the backups it made.
+Sessions are journaled under <backup.path>/sessions.`,
+	Example: `  goscaffold undo
+  goscaffold undo --list
+  goscaffold undo --session 20240102T150405`,
+	RunE: runUndo,
+}
+
+func init() {
+	undoCmd.Flags().StringVarP(&undoSession, "session", "s", "", "Undo this session instead of the latest")
+	undoCmd.Flags().BoolVarP(&undoList, "list", "l", false, "List recorded sessions")
+	undoCmd.MarkFlagsMutuallyExclusive("session", "list")
+
+	rootCmd.AddCommand(undoCmd)
+}
+
+func runUndo(cmd *cobra.Command, args []string) error {
+	bm, err := newBackupManager()
+	if err != nil {
+		return err
+	}
+
+	if undoList {
+		sessions, err := bm.Sessions()
+		if err != nil {
+			return fmt.Errorf("list sessions: %w", err)
+		}
+		if len(sessions) == 0 {
+			log.Info("No import sessions recorded")
+			return nil
+		}
+		for _, s := range sessions {
+			log.Info(fmt.Sprintf("%s (%s)", s.ID, s.Time.Format("2006-01-02 15:04:05")),
+				"created", len(s.Created), "backups", len(s.Backups))
+		}
+		return nil
+	}
+
+	var s *backup.Session
+	if undoSession != "" {
+		var err error
+		if s, err = bm.Session(undoSession); err != nil {
+			return err
+		}
+	} else {
+		sessions, err := bm.Sessions()
+		if err != nil {
+			return fmt.Errorf("list sessions: %w", err)
+		}
+		if len(sessions) == 0 {
+			return fmt.Errorf("no import sessions to undo")
+		}
+		s = sessions[0]
+	}
+
+	if err := bm.Undo(s); err != nil {
+		return fmt.Errorf("undo %s: %w", s.ID, err)
+	}
+	log.Info("✨ Undid import", "session", s.ID, "removed", len(s.Created), "restored", len(s.Backups))
+	return nil
+}
@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+
+	"goscaffold/internal/models"
+	"goscaffold/pkg/backup"
+	"goscaffold/pkg/parser"
+	"goscaffold/pkg/validator"
+)
+
+var validateStrict bool
+
+var validateCmd = &cobra.Command{
+	Use:   "validate [path...]",
+	Short: "Run validators without importing",
+	Long: `Run configured and built-in validators against a directory tree or the
+code blocks of a chat file (.md, .markdown, .txt). Nothing is written.`,
+	Example: `  goscaffold validate
+  goscaffold validate ./internal chat.md
+  goscaffold validate --strict chat.md`,
+	RunE: runValidate,
+}
+
+func init() {
+	validateCmd.Flags().BoolVar(&validateStrict, "strict", false, "Treat every validator as strict")
+
+	rootCmd.AddCommand(validateCmd)
+}
+
+func runValidate(cmd *cobra.Command, args []string) error {
+	ctx := cmd.Context()
+	if len(args) == 0 {
+		args = []string{"."}
+	}
+
+	var files []models.File
+	for _, arg := range args {
+		found, err := collectValidateFiles(arg)
+		if err != nil {
+			return err
+		}
+		files = append(files, found...)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "STATUS\tFILE\tDETAIL")
+
+	var passed, failed, strictFailed, unchecked int
+	for _, f := range files {
+		v, err := validator.GetForFile(f.TypedPath())
+		if err != nil {
+			unchecked++
+			continue
+		}
+
+		if err := v.Validate(ctx, f.TypedPath(), f.Code); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			failed++
+			if validateStrict || v.Strict {
+				strictFailed++
+			}
+			detail := strings.ReplaceAll(strings.TrimSpace(err.Error()), "\n", " ")
+			fmt.Fprintf(tw, "✗ FAIL\t%s\t%s\n", f.Path, detail)
+			continue
+		}
+		passed++
+		fmt.Fprintf(tw, "✓ PASS\t%s\t\n", f.Path)
+	}
+	tw.Flush()
+
+	fmt.Printf("\n%d passed, %d failed, %d without validator\n", passed, failed, unchecked)
+	if strictFailed > 0 {
+		return fmt.Errorf("%d file(s) failed strict validation", strictFailed)
+	}
+	return nil
+}
+
+// collectValidateFiles parses chat files into their code blocks and reads
+// every other file, walking directories.
+func collectValidateFiles(path string) ([]models.File, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		if isChatFile(path) {
+			return parser.Parse(string(data), parser.FormatAuto), nil
+		}
+		return []models.File{{Path: path, Code: string(data)}}, nil
+	}
+
+	bm, err := newBackupManager()
+	if err != nil {
+		return nil, err
+	}
+	backupDir := bm.Dir()
+
+	var files []models.File
+	err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != path && (d.Name() == ".git" || d.Name() == backup.Dir || filepath.Clean(p) == backupDir) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		files = append(files, models.File{Path: p, Code: string(data)})
+		return nil
+	})
+	return files, err
+}
+
+func isChatFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".markdown", ".txt":
+		return true
+	}
+	return false
+}
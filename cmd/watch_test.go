@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func touch(t *testing.T, path string, mtime time.Time) {
+	t.Helper()
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatchTargetChangedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "chat.md")
+	mustWrite(t, path, "v1")
+	start := time.Now().Add(-time.Hour)
+	touch(t, path, start)
+
+	target, err := newWatchTarget(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The first call records the startup mtime; runWatchMode only imports
+	// it with --watch-initial.
+	if got := target.changed(); !slices.Equal(got, []string{path}) {
+		t.Fatalf("initial changed() = %v, want [%s]", got, path)
+	}
+	if got := target.changed(); len(got) != 0 {
+		t.Errorf("changed() without a new mtime = %v", got)
+	}
+
+	touch(t, path, start.Add(-time.Minute))
+	if got := target.changed(); len(got) != 0 {
+		t.Errorf("changed() after an older mtime = %v", got)
+	}
+
+	touch(t, path, start.Add(time.Second))
+	if got := target.changed(); !slices.Equal(got, []string{path}) {
+		t.Errorf("changed() after a newer mtime = %v, want [%s]", got, path)
+	}
+	if got := target.changed(); len(got) != 0 {
+		t.Errorf("changed() reported the same mtime twice: %v", got)
+	}
+}
+
+func TestWatchTargetChangedDir(t *testing.T) {
+	dir := t.TempDir()
+	a, b := filepath.Join(dir, "a.md"), filepath.Join(dir, "b.md")
+	mustWrite(t, a, "a")
+	mustWrite(t, filepath.Join(dir, "notes.txt"), "not a chat file")
+
+	target, err := newWatchTarget(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := target.changed(); !slices.Equal(got, []string{a}) {
+		t.Fatalf("initial changed() = %v, want [%s]", got, a)
+	}
+
+	// A new chat file counts as changed; the unchanged one does not.
+	mustWrite(t, b, "b")
+	if got := target.changed(); !slices.Equal(got, []string{b}) {
+		t.Errorf("changed() after adding b.md = %v, want [%s]", got, b)
+	}
+
+	info, err := os.Stat(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	touch(t, a, info.ModTime().Add(time.Second))
+	if got := target.changed(); !slices.Equal(got, []string{a}) {
+		t.Errorf("changed() after touching a.md = %v, want [%s]", got, a)
+	}
+}
@@ -0,0 +1,110 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// MaxPathDepth is the maximum number of path components a File may have.
+const MaxPathDepth = 20
+
+type File struct {
+	Path string
+	Code string
+
+	// Action is what import does with the file; see Action. NewPath is the
+	// destination of a rename.
+	Action  Action
+	NewPath string
+
+	// Encoding is the transfer encoding the block declared, e.g. "base64".
+	// Code holds the decoded content once the parser has decoded it.
+	Encoding string
+
+	// Language is the language named by the block's fence (```go) or
+	// front-matter lang: field, if any.
+	Language string
+
+	// Source is the input the file was parsed from when an import reads
+	// several.
+	Source string
+
+	// StartLine and EndLine are the 1-based input lines of the opening and
+	// closing fence that produced this file.
+	StartLine int
+	EndLine   int
+
+	// CRLF records that the input used \r\n line endings. Code itself is
+	// always normalized to \n.
+	CRLF bool
+
+	// Warnings are non-fatal problems found while parsing this file.
+	Warnings []string
+}
+
+// Action is what import does with a File. The zero value writes Code to
+// Path, creating or replacing it, as do ActionCreate and ActionUpdate, which
+// only record what the input said.
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+	ActionRename Action = "rename"
+)
+
+// ParseAction parses an action name, case-insensitively.
+func ParseAction(s string) (Action, error) {
+	switch a := Action(strings.ToLower(s)); a {
+	case ActionCreate, ActionUpdate, ActionDelete, ActionRename:
+		return a, nil
+	default:
+		return "", fmt.Errorf("unknown action %q", s)
+	}
+}
+
+// Writes reports whether the file's action writes Code to Path.
+func (f File) Writes() bool {
+	return f.Action == "" || f.Action == ActionCreate || f.Action == ActionUpdate
+}
+
+// Validate checks that Path, and NewPath for a rename, are non-empty, clean,
+// relative paths that stay inside the working directory.
+func (f File) Validate() error {
+	if err := validatePath(f.Path); err != nil {
+		return err
+	}
+	if f.Action == ActionRename {
+		if f.NewPath == "" {
+			return fmt.Errorf("rename of %q has no target path", f.Path)
+		}
+		return validatePath(f.NewPath)
+	}
+	return nil
+}
+
+func validatePath(p string) error {
+	switch {
+	case strings.TrimSpace(p) == "":
+		return errors.New("empty path")
+	case filepath.IsAbs(p) || strings.HasPrefix(p, "/") || filepath.VolumeName(p) != "":
+		return fmt.Errorf("path %q is absolute", p)
+	}
+
+	for _, part := range strings.Split(filepath.ToSlash(p), "/") {
+		if part == ".." {
+			return fmt.Errorf("path %q escapes the working directory", p)
+		}
+	}
+
+	if clean := filepath.Clean(p); clean != p {
+		return fmt.Errorf("path %q is not clean (want %q)", p, clean)
+	}
+	if depth := strings.Count(filepath.ToSlash(p), "/") + 1; depth > MaxPathDepth {
+		return fmt.Errorf("path %q is %d levels deep (max %d)", p, depth, MaxPathDepth)
+	}
+	return nil
+}
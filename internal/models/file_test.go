@@ -0,0 +1,43 @@
+package models
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFileValidate(t *testing.T) {
+	deep := strings.Repeat("d/", MaxPathDepth) + "x.go"
+
+	tests := []struct {
+		name    string
+		file    File
+		wantErr string
+	}{
+		{"simple", File{Path: "main.go"}, ""},
+		{"nested", File{Path: "cmd/app/main.go"}, ""},
+		{"dots in name", File{Path: "a/..b/c..go"}, ""},
+		{"rename", File{Path: "a.go", Action: ActionRename, NewPath: "b/a.go"}, ""},
+		{"empty", File{Path: ""}, "empty path"},
+		{"blank", File{Path: "  "}, "empty path"},
+		{"absolute", File{Path: "/etc/passwd"}, "is absolute"},
+		{"parent", File{Path: "../x.go"}, "escapes"},
+		{"inner parent", File{Path: "a/../../x.go"}, "escapes"},
+		{"not clean", File{Path: "a//b.go"}, "not clean"},
+		{"dot segment", File{Path: "./a.go"}, "not clean"},
+		{"trailing slash", File{Path: "a/"}, "not clean"},
+		{"too deep", File{Path: deep}, "levels deep"},
+		{"rename without target", File{Path: "a.go", Action: ActionRename}, "no target"},
+		{"rename outside", File{Path: "a.go", Action: ActionRename, NewPath: "../a.go"}, "escapes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.file.Validate()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("Validate(%q) = %v, want nil", tt.file.Path, err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Errorf("Validate(%q) = %v, want error containing %q", tt.file.Path, err, tt.wantErr)
+			}
+		})
+	}
+}
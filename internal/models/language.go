@@ -0,0 +1,55 @@
+package models
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// languageExts maps fence languages to file extensions for files whose path
+// has none.
+var languageExts = map[string]string{
+	"go":         ".go",
+	"golang":     ".go",
+	"python":     ".py",
+	"py":         ".py",
+	"javascript": ".js",
+	"js":         ".js",
+	"typescript": ".ts",
+	"ts":         ".ts",
+	"rust":       ".rs",
+	"rs":         ".rs",
+	"bash":       ".sh",
+	"sh":         ".sh",
+	"shell":      ".sh",
+	"zsh":        ".sh",
+	"yaml":       ".yaml",
+	"yml":        ".yaml",
+	"json":       ".json",
+	"toml":       ".toml",
+	"sql":        ".sql",
+	"html":       ".html",
+	"css":        ".css",
+	"markdown":   ".md",
+	"md":         ".md",
+	"dockerfile": ".dockerfile",
+	"makefile":   ".mk",
+	"make":       ".mk",
+}
+
+// Ext returns the extension of Path, or the one implied by Language when
+// Path has none. It is empty if neither says.
+func (f File) Ext() string {
+	if ext := filepath.Ext(f.Path); ext != "" {
+		return ext
+	}
+	return languageExts[strings.ToLower(f.Language)]
+}
+
+// TypedPath returns Path with the extension implied by Language appended
+// when Path has none, for choosing validators and highlighting.
+func (f File) TypedPath() string {
+	if filepath.Ext(f.Path) != "" {
+		return f.Path
+	}
+	return f.Path + f.Ext()
+}
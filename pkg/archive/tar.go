@@ -0,0 +1,40 @@
+package archive
+
+import (
+	"archive/tar"
+	"fmt"
+	"io"
+	"path/filepath"
+	"time"
+
+	"goscaffold/internal/models"
+)
+
+// FileMode is the mode given to every archived file, matching what a
+// filesystem import writes.
+const FileMode = 0644
+
+// WriteTar writes files into a tar stream on w, using each file's path as
+// the entry name.
+func WriteTar(w io.Writer, files []models.File) error {
+	tw := tar.NewWriter(w)
+	now := time.Now()
+
+	for _, f := range files {
+		hdr := &tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     filepath.ToSlash(f.Path),
+			Mode:     FileMode,
+			Size:     int64(len(f.Code)),
+			ModTime:  now,
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("tar header %s: %w", f.Path, err)
+		}
+		if _, err := io.WriteString(tw, f.Code); err != nil {
+			return fmt.Errorf("tar write %s: %w", f.Path, err)
+		}
+	}
+
+	return tw.Close()
+}
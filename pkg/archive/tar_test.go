@@ -0,0 +1,56 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"goscaffold/internal/models"
+)
+
+func TestWriteTar(t *testing.T) {
+	files := []models.File{
+		{Path: "go.mod", Code: "module demo\n"},
+		{Path: "cmd/demo/main.go", Code: "package main\n\nfunc main() {}\n"},
+		{Path: "empty.txt", Code: ""},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteTar(&buf, files); err != nil {
+		t.Fatal(err)
+	}
+
+	tr := tar.NewReader(&buf)
+	for i := 0; ; i++ {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			if i != len(files) {
+				t.Fatalf("archive has %d entries, want %d", i, len(files))
+			}
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i >= len(files) {
+			t.Fatalf("unexpected entry %s", hdr.Name)
+		}
+
+		want := files[i]
+		if hdr.Name != want.Path {
+			t.Errorf("entry %d name = %s, want %s", i, hdr.Name, want.Path)
+		}
+		if hdr.Typeflag != tar.TypeReg || hdr.Mode != FileMode {
+			t.Errorf("%s: type %c mode %o, want regular file mode %o", hdr.Name, hdr.Typeflag, hdr.Mode, FileMode)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != want.Code {
+			t.Errorf("%s content = %q, want %q", hdr.Name, data, want.Code)
+		}
+	}
+}
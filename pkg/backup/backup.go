@@ -0,0 +1,450 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Dir is the default backup directory, relative to the working directory.
+	Dir         = ".goscaffold-backup"
+	Suffix      = ".backup"
+	stampLayout = "20060102T150405"
+
+	absDir = "_abs"
+	upDir  = "_up"
+)
+
+type Manager struct {
+	dir       string
+	retention string
+	keep      int
+}
+
+type BackupEntry struct {
+	Original string
+	Path     string
+	Time     time.Time
+}
+
+// NewManager creates a Manager storing backups under dir (Dir if empty),
+// pruning those older than retention and keeping at most keep backups per
+// file (0 keeps all of them). A relative dir is resolved against the working
+// directory. With an absolute dir, which may be shared between projects,
+// backups are keyed by the absolute path of the original file.
+func NewManager(dir, retention string, keep int) *Manager {
+	if dir == "" {
+		dir = Dir
+	}
+	return &Manager{
+		dir:       filepath.Clean(dir),
+		retention: retention,
+		keep:      keep,
+	}
+}
+
+// Dir returns the backup directory.
+func (m *Manager) Dir() string {
+	return m.dir
+}
+
+// key returns the path backups of path are stored under.
+func (m *Manager) key(path string) string {
+	if filepath.IsAbs(m.dir) {
+		if abs, err := filepath.Abs(path); err == nil {
+			return abs
+		}
+	}
+	return filepath.Clean(path)
+}
+
+// Backup copies path to a timestamped file under the backup directory and
+// returns the backup path, or "" if path does not exist.
+func (m *Manager) Backup(path string) (string, error) {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", path, err)
+	}
+
+	base := filepath.Join(m.dir, mirror(m.key(path)))
+	if err := os.MkdirAll(filepath.Dir(base), 0755); err != nil {
+		return "", fmt.Errorf("mkdir %s: %w", filepath.Dir(base), err)
+	}
+
+	dst, err := create(base, time.Now().Format(stampLayout), data, info.Mode().Perm())
+	if err != nil {
+		return "", err
+	}
+
+	if err := m.trim(base); err != nil {
+		return dst, err
+	}
+	return dst, nil
+}
+
+// mirror maps a source path to a relative path inside the backup directory
+// that is unique per source: absolute paths live under _abs and parent
+// references become _up, so nothing escapes the backup directory. Real
+// components spelled like those markers get an extra leading underscore
+// (_abs becomes __abs, __up becomes ___up) so they cannot be confused.
+func mirror(path string) string {
+	path = filepath.Clean(path)
+
+	var parts []string
+	if filepath.IsAbs(path) {
+		parts = append(parts, absDir)
+		path = strings.TrimPrefix(path, filepath.VolumeName(path))
+	}
+	for _, p := range strings.Split(path, string(filepath.Separator)) {
+		switch p {
+		case "":
+		case "..":
+			parts = append(parts, upDir)
+		default:
+			if reserved(p) {
+				p = "_" + p
+			}
+			parts = append(parts, p)
+		}
+	}
+	return filepath.Join(parts...)
+}
+
+// reserved reports whether a path component is a mirror marker with any
+// number of leading underscores, and so must be escaped by mirror.
+func reserved(name string) bool {
+	trimmed := strings.TrimLeft(name, "_")
+	if trimmed == name {
+		return false
+	}
+	marker := "_" + trimmed
+	return marker == absDir || marker == upDir
+}
+
+// unmirror reverses mirror.
+func unmirror(rel string) string {
+	parts := strings.Split(rel, string(filepath.Separator))
+	abs := len(parts) > 0 && parts[0] == absDir
+	if abs {
+		parts = parts[1:]
+	}
+	for i, p := range parts {
+		switch {
+		case p == upDir:
+			parts[i] = ".."
+		case strings.HasPrefix(p, "__") && reserved(p):
+			parts[i] = p[1:]
+		}
+	}
+
+	path := filepath.Join(parts...)
+	if abs {
+		path = string(filepath.Separator) + path
+	}
+	return path
+}
+
+// create exclusively creates base.<stamp>.backup, appending a -N sequence to
+// the stamp when concurrent backups land in the same second.
+func create(base, stamp string, data []byte, perm fs.FileMode) (string, error) {
+	for seq := 0; ; seq++ {
+		name := stamp
+		if seq > 0 {
+			name = fmt.Sprintf("%s-%d", stamp, seq)
+		}
+		dst := base + "." + name + Suffix
+
+		f, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, perm)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("create %s: %w", dst, err)
+		}
+
+		_, err = f.Write(data)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(dst)
+			return "", fmt.Errorf("write %s: %w", dst, err)
+		}
+		return dst, nil
+	}
+}
+
+// parseStamp parses a backup stamp with its optional -N sequence.
+func parseStamp(s string) (time.Time, int, bool) {
+	stamp, seqStr, hasSeq := strings.Cut(s, "-")
+	t, err := time.ParseInLocation(stampLayout, stamp, time.Local)
+	if err != nil {
+		return time.Time{}, 0, false
+	}
+
+	seq := 0
+	if hasSeq {
+		if seq, err = strconv.Atoi(seqStr); err != nil || seq < 1 {
+			return time.Time{}, 0, false
+		}
+	}
+	return t, seq, true
+}
+
+// trim removes all but the newest m.keep backups sharing base.
+func (m *Manager) trim(base string) error {
+	if m.keep <= 0 {
+		return nil
+	}
+
+	dirEntries, err := os.ReadDir(filepath.Dir(base))
+	if err != nil {
+		return err
+	}
+
+	type backupStamp struct {
+		name string
+		t    time.Time
+		seq  int
+	}
+
+	prefix := filepath.Base(base) + "."
+	var stamps []backupStamp
+	for _, de := range dirEntries {
+		name := de.Name()
+		if de.IsDir() || !strings.HasPrefix(name, prefix) || !strings.HasSuffix(name, Suffix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimPrefix(name, prefix), Suffix)
+		if t, seq, ok := parseStamp(stamp); ok {
+			stamps = append(stamps, backupStamp{stamp, t, seq})
+		}
+	}
+	if len(stamps) <= m.keep {
+		return nil
+	}
+
+	sort.Slice(stamps, func(i, j int) bool {
+		if !stamps[i].t.Equal(stamps[j].t) {
+			return stamps[i].t.After(stamps[j].t)
+		}
+		return stamps[i].seq > stamps[j].seq
+	})
+	for _, stamp := range stamps[m.keep:] {
+		old := base + "." + stamp.name + Suffix
+		if err := os.Remove(old); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("remove %s: %w", old, err)
+		}
+	}
+	return nil
+}
+
+// List returns every backup under the backup directory, newest first.
+func (m *Manager) List() ([]BackupEntry, error) {
+	var entries []BackupEntry
+
+	err := filepath.WalkDir(m.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, Suffix) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(m.dir, path)
+		if err != nil {
+			return err
+		}
+		original := strings.TrimSuffix(rel, Suffix)
+
+		var ts time.Time
+		if ext := filepath.Ext(original); ext != "" {
+			if t, _, ok := parseStamp(ext[1:]); ok {
+				ts = t
+				original = strings.TrimSuffix(original, ext)
+			}
+		}
+		original = unmirror(original)
+		if ts.IsZero() {
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			ts = info.ModTime()
+		}
+
+		entries = append(entries, BackupEntry{Original: original, Path: path, Time: ts})
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if !entries[i].Time.Equal(entries[j].Time) {
+			return entries[i].Time.After(entries[j].Time)
+		}
+		// Same-second backups carry a higher -N sequence when newer.
+		return entries[i].Path > entries[j].Path
+	})
+	return entries, nil
+}
+
+// Latest returns the most recent backup of path, if any.
+func (m *Manager) Latest(path string) (BackupEntry, bool, error) {
+	entries, err := m.List()
+	if err != nil {
+		return BackupEntry{}, false, err
+	}
+
+	want := m.key(path)
+	for _, e := range entries {
+		if e.Original == want {
+			return e, true, nil
+		}
+	}
+	return BackupEntry{}, false, nil
+}
+
+// Restore moves the most recent backup of path back to its original location.
+func (m *Manager) Restore(path string) error {
+	e, ok, err := m.Latest(path)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("no backup found for %s", path)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(e.Original), 0755); err != nil {
+		return fmt.Errorf("mkdir %s: %w", filepath.Dir(e.Original), err)
+	}
+	if err := os.Rename(e.Path, e.Original); err != nil {
+		return fmt.Errorf("restore %s: %w", e.Original, err)
+	}
+	return nil
+}
+
+// ParseRetention parses a retention window such as "7d", "12h" or "2w".
+// An empty or zero retention disables pruning and returns 0.
+func ParseRetention(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "" || s == "0" {
+		return 0, nil
+	}
+
+	unit := map[byte]time.Duration{
+		'h': time.Hour,
+		'd': 24 * time.Hour,
+		'w': 7 * 24 * time.Hour,
+	}[s[len(s)-1]]
+	if unit == 0 {
+		return 0, fmt.Errorf("invalid retention %q: expected suffix h, d or w", s)
+	}
+
+	n, err := strconv.Atoi(s[:len(s)-1])
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid retention %q", s)
+	}
+	return time.Duration(n) * unit, nil
+}
+
+// Prune deletes backups, and merge bases, older than the retention window.
+func (m *Manager) Prune() error {
+	window, err := ParseRetention(m.retention)
+	if err != nil {
+		return err
+	}
+	if window == 0 {
+		return nil
+	}
+
+	expired, err := m.Expired(window)
+	if err != nil {
+		return err
+	}
+	for _, e := range expired {
+		if err := m.Remove(e); err != nil {
+			return fmt.Errorf("prune: %w", err)
+		}
+	}
+	return nil
+}
+
+// Expired returns the backups and merge bases older than window, newest
+// first.
+func (m *Manager) Expired(window time.Duration) ([]BackupEntry, error) {
+	entries, err := m.List()
+	if err != nil {
+		return nil, err
+	}
+	bases, err := m.listBases()
+	if err != nil {
+		return nil, err
+	}
+	entries = append(entries, bases...)
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.After(entries[j].Time)
+	})
+
+	cutoff := time.Now().Add(-window)
+	var expired []BackupEntry
+	for _, e := range entries {
+		if e.Time.Before(cutoff) {
+			expired = append(expired, e)
+		}
+	}
+	return expired, nil
+}
+
+// Remove deletes one backup. It refuses a path outside the backup
+// directory, and a backup that is already gone is not an error.
+func (m *Manager) Remove(e BackupEntry) error {
+	rel, err := filepath.Rel(m.dir, e.Path)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("refusing to remove %s: not inside %s", e.Path, m.dir)
+	}
+	if err := os.Remove(e.Path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove %s: %w", e.Path, err)
+	}
+	return nil
+}
+
+// RemoveAll deletes the backup directory, sessions and merge bases included. It refuses a
+// directory that is, or contains, the working directory, which a
+// misconfigured backup.path such as "." or "~" would be.
+func (m *Manager) RemoveAll() error {
+	dir, err := filepath.Abs(m.dir)
+	if err != nil {
+		return err
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	if rel, err := filepath.Rel(dir, wd); err == nil && !strings.HasPrefix(rel, "..") {
+		return fmt.Errorf("refusing to remove %s: it contains the working directory", m.dir)
+	}
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("remove %s: %w", m.dir, err)
+	}
+	return nil
+}
@@ -0,0 +1,273 @@
+package backup
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestBackupConfiguredDir(t *testing.T) {
+	for _, abs := range []bool{false, true} {
+		t.Run(map[bool]string{false: "relative", true: "absolute"}[abs], func(t *testing.T) {
+			work := t.TempDir()
+			t.Chdir(work)
+
+			dir := "backups"
+			if abs {
+				dir = filepath.Join(t.TempDir(), "shared")
+			}
+			m := NewManager(dir, "0", 0)
+
+			writeFile(t, "cmd/main.go", "v1")
+			dst, err := m.Backup("cmd/main.go")
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			want := filepath.Join(dir, "cmd", "main.go")
+			if abs {
+				want = filepath.Join(dir, mirror(filepath.Join(work, "cmd", "main.go")))
+			}
+			if !strings.HasPrefix(dst, want+".") || !strings.HasSuffix(dst, Suffix) {
+				t.Errorf("backup at %s, want %s.<stamp>%s", dst, want, Suffix)
+			}
+			if _, err := os.Stat(Dir); !os.IsNotExist(err) {
+				t.Errorf("default %s created alongside configured dir", Dir)
+			}
+
+			e, ok, err := m.Latest("cmd/main.go")
+			if err != nil || !ok {
+				t.Fatalf("Latest = %v, %v, %v", e, ok, err)
+			}
+			if e.Path != dst {
+				t.Errorf("Latest path = %s, want %s", e.Path, dst)
+			}
+
+			writeFile(t, "cmd/main.go", "v2")
+			if err := m.Restore("cmd/main.go"); err != nil {
+				t.Fatal(err)
+			}
+			if got := readFile(t, "cmd/main.go"); got != "v1" {
+				t.Errorf("restored %q, want v1", got)
+			}
+		})
+	}
+}
+
+func TestBackupConcurrentSameName(t *testing.T) {
+	t.Chdir(t.TempDir())
+	m := NewManager("backups", "0", 0)
+
+	paths := []string{"a/x.go", "b/x.go", "a/b/x.go", "x.go"}
+	for _, p := range paths {
+		writeFile(t, p, "content of "+p)
+	}
+
+	// Several rounds land in the same second, exercising the -N sequence.
+	const rounds = 3
+	var wg sync.WaitGroup
+	errs := make(chan error, rounds*len(paths))
+	for range rounds {
+		for _, p := range paths {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				if _, err := m.Backup(p); err != nil {
+					errs <- err
+				}
+			}()
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+
+	entries, err := m.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != rounds*len(paths) {
+		t.Fatalf("got %d backups, want %d", len(entries), rounds*len(paths))
+	}
+	for _, e := range entries {
+		if got, want := readFile(t, e.Path), "content of "+filepath.ToSlash(e.Original); got != want {
+			t.Errorf("%s holds %q, want %q", e.Path, got, want)
+		}
+	}
+	for _, p := range paths {
+		if _, ok, _ := m.Latest(p); !ok {
+			t.Errorf("no backup of %s", p)
+		}
+	}
+}
+
+func TestMirrorRoundTrip(t *testing.T) {
+	paths := []string{
+		"x.go",
+		"a/b/x.go",
+		"../up.go",
+		"_abs/x.go",
+		"__abs/x.go",
+		"a/_up/x.go",
+		"_up",
+		"../_up/__up",
+		"_internal/x.go",
+		"/srv/app/_abs/x.go",
+	}
+	seen := make(map[string]string)
+	for _, p := range paths {
+		p = filepath.FromSlash(p)
+		m := mirror(p)
+		if filepath.IsAbs(m) || m == ".." || strings.HasPrefix(m, ".."+string(filepath.Separator)) {
+			t.Errorf("mirror(%s) = %s escapes the backup directory", p, m)
+		}
+		if other, ok := seen[m]; ok {
+			t.Errorf("mirror(%s) = mirror(%s) = %s", p, other, m)
+		}
+		seen[m] = p
+		if got := unmirror(m); got != p {
+			t.Errorf("unmirror(mirror(%s)) = %s (via %s)", p, got, m)
+		}
+	}
+}
+
+func TestBackupReservedDirName(t *testing.T) {
+	t.Chdir(t.TempDir())
+	m := NewManager("backups", "0", 0)
+
+	writeFile(t, "_abs/x.go", "relative")
+	if _, err := m.Backup("_abs/x.go"); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := m.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Original != filepath.FromSlash("_abs/x.go") {
+		t.Fatalf("List = %+v, want one backup of _abs/x.go", entries)
+	}
+}
+
+func TestParseRetention(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"0", 0, false},
+		{" 7d ", 7 * 24 * time.Hour, false},
+		{"12h", 12 * time.Hour, false},
+		{"2w", 14 * 24 * time.Hour, false},
+		{"0d", 0, false},
+		{"d", 0, true},
+		{"7", 0, true},
+		{"7m", 0, true},
+		{"-1d", 0, true},
+		{"1.5d", 0, true},
+		{"7D", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseRetention(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseRetention(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseRetention(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+	old := time.Now().Add(-30 * 24 * time.Hour).Format(stampLayout)
+	recent := time.Now().Add(-time.Hour).Format(stampLayout)
+
+	tests := []struct {
+		retention string
+		want      []string
+	}{
+		{"7d", []string{"x.go." + recent + Suffix}},
+		{"0", []string{"x.go." + old + Suffix, "x.go." + recent + Suffix}},
+		{"", []string{"x.go." + old + Suffix, "x.go." + recent + Suffix}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.retention, func(t *testing.T) {
+			dir := t.TempDir()
+			writeFile(t, filepath.Join(dir, "x.go."+old+Suffix), "old")
+			writeFile(t, filepath.Join(dir, "x.go."+recent+Suffix), "recent")
+
+			if err := NewManager(dir, tt.retention, 0).Prune(); err != nil {
+				t.Fatal(err)
+			}
+
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, e := range entries {
+				got = append(got, e.Name())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("kept %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPruneBases(t *testing.T) {
+	dir := t.TempDir()
+	m := NewManager(dir, "7d", 0)
+	for _, name := range []string{"old.go", "new.go"} {
+		if err := m.SaveBase(name, []byte(name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	month := time.Now().Add(-30 * 24 * time.Hour)
+	if err := os.Chtimes(m.basePath("old.go"), month, month); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := m.Prune(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok, _ := m.Base("old.go"); ok {
+		t.Error("base older than the retention was kept")
+	}
+	if _, ok, _ := m.Base("new.go"); !ok {
+		t.Error("recent base was pruned")
+	}
+}
+
+func TestPruneInvalidRetention(t *testing.T) {
+	if err := NewManager(t.TempDir(), "soon", 0).Prune(); err == nil {
+		t.Error("Prune with an invalid retention succeeded")
+	}
+}
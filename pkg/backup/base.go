@@ -0,0 +1,82 @@
+package backup
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	basesDir   = "bases"
+	baseSuffix = ".base"
+)
+
+// basePath returns where the last imported content of path is kept.
+func (m *Manager) basePath(path string) string {
+	return filepath.Join(m.dir, basesDir, mirror(m.key(path))) + baseSuffix
+}
+
+// SaveBase records content as what was last imported to path. A later
+// import merging into path uses it as the common ancestor of the user's
+// edits and the new content. Bases live under the backup directory and are
+// pruned and cleaned with the backups.
+func (m *Manager) SaveBase(path string, content []byte) error {
+	dst := m.basePath(path)
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("mkdir %s: %w", filepath.Dir(dst), err)
+	}
+	if err := os.WriteFile(dst, content, 0644); err != nil {
+		return fmt.Errorf("write %s: %w", dst, err)
+	}
+	return nil
+}
+
+// Base returns the content last recorded by SaveBase for path, if any.
+func (m *Manager) Base(path string) (string, bool, error) {
+	data, err := os.ReadFile(m.basePath(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return string(data), true, nil
+}
+
+// listBases returns the recorded merge bases, timed by when they were last
+// written.
+func (m *Manager) listBases() ([]BackupEntry, error) {
+	root := filepath.Join(m.dir, basesDir)
+	var entries []BackupEntry
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(path, baseSuffix) {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		original := unmirror(strings.TrimSuffix(rel, baseSuffix))
+		entries = append(entries, BackupEntry{Original: original, Path: path, Time: info.ModTime()})
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
@@ -0,0 +1,200 @@
+package backup
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const sessionsDir = "sessions"
+
+// Session journals one import: the files it created and the backups it made
+// of files it overwrote. It is safe for concurrent use.
+type Session struct {
+	mu sync.Mutex
+
+	ID      string          `json:"id"`
+	Time    time.Time       `json:"time"`
+	Created []string        `json:"created"`
+	Backups []SessionBackup `json:"backups"`
+}
+
+type SessionBackup struct {
+	Original string `json:"original"`
+	Path     string `json:"path"`
+}
+
+func NewSession() *Session {
+	return &Session{Time: time.Now()}
+}
+
+func (s *Session) AddCreated(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Created = append(s.Created, path)
+}
+
+func (s *Session) AddBackup(original, path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Backups = append(s.Backups, SessionBackup{Original: original, Path: path})
+}
+
+// Empty reports whether the session recorded nothing worth undoing.
+func (s *Session) Empty() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.Created) == 0 && len(s.Backups) == 0
+}
+
+// SaveSession writes s to <dir>/sessions/<id>.json, assigning its ID from
+// its start time. Paths are stored the way backups are keyed, so they are
+// absolute when the backup directory is.
+func (m *Manager) SaveSession(s *Session) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, p := range s.Created {
+		s.Created[i] = m.key(p)
+	}
+	for i, b := range s.Backups {
+		s.Backups[i].Original = m.key(b.Original)
+	}
+
+	dir := filepath.Join(m.dir, sessionsDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("mkdir %s: %w", dir, err)
+	}
+
+	stamp := s.Time.Format(stampLayout)
+	for seq := 0; ; seq++ {
+		s.ID = stamp
+		if seq > 0 {
+			s.ID = fmt.Sprintf("%s-%d", stamp, seq)
+		}
+
+		data, err := json.MarshalIndent(s, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		dst := filepath.Join(dir, s.ID+".json")
+		f, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if errors.Is(err, fs.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("create %s: %w", dst, err)
+		}
+
+		_, err = f.Write(data)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			os.Remove(dst)
+			return fmt.Errorf("write %s: %w", dst, err)
+		}
+		return nil
+	}
+}
+
+// Sessions returns the journaled sessions, newest first.
+func (m *Manager) Sessions() ([]*Session, error) {
+	dir := filepath.Join(m.dir, sessionsDir)
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var sessions []*Session
+	for _, e := range entries {
+		id, ok := strings.CutSuffix(e.Name(), ".json")
+		if e.IsDir() || !ok {
+			continue
+		}
+		s, err := m.Session(id)
+		if err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		ti, si, _ := parseStamp(sessions[i].ID)
+		tj, sj, _ := parseStamp(sessions[j].ID)
+		if !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return si > sj
+	})
+	return sessions, nil
+}
+
+// Session loads the session with the given ID.
+func (m *Manager) Session(id string) (*Session, error) {
+	if _, _, ok := parseStamp(id); !ok {
+		return nil, fmt.Errorf("invalid session id %q", id)
+	}
+
+	path := filepath.Join(m.dir, sessionsDir, id+".json")
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("session %s not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Session{}
+	if err := json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	s.ID = id
+	return s, nil
+}
+
+// Undo deletes the files s created and moves its backups back into place,
+// then removes the session from the journal. It carries on past individual
+// failures and returns them joined.
+func (m *Manager) Undo(s *Session) error {
+	var errs []error
+	for _, path := range s.Created {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("remove %s: %w", path, err))
+		}
+	}
+
+	for _, b := range s.Backups {
+		if err := os.MkdirAll(filepath.Dir(b.Original), 0755); err != nil {
+			errs = append(errs, fmt.Errorf("mkdir %s: %w", filepath.Dir(b.Original), err))
+			continue
+		}
+		if err := os.Rename(b.Path, b.Original); err != nil {
+			errs = append(errs, fmt.Errorf("restore %s: %w", b.Original, err))
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+
+	path := filepath.Join(m.dir, sessionsDir, s.ID+".json")
+	if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("remove %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package charset
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+type Encoding string
+
+const (
+	UTF8    Encoding = "utf-8"
+	UTF16LE Encoding = "utf-16le"
+	UTF16BE Encoding = "utf-16be"
+	Latin1  Encoding = "latin-1"
+)
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// Parse returns the encoding named by s, accepting common spellings such as
+// "utf8", "UTF-16LE" and "iso-8859-1".
+func Parse(s string) (Encoding, error) {
+	switch strings.ReplaceAll(strings.ToLower(s), "_", "-") {
+	case "utf-8", "utf8":
+		return UTF8, nil
+	case "utf-16le", "utf16le", "utf-16", "utf16":
+		return UTF16LE, nil
+	case "utf-16be", "utf16be":
+		return UTF16BE, nil
+	case "latin-1", "latin1", "iso-8859-1", "iso8859-1":
+		return Latin1, nil
+	default:
+		return "", fmt.Errorf("unknown encoding %q (want utf-8, utf-16le, utf-16be or latin-1)", s)
+	}
+}
+
+// TrimBOM drops the byte order mark of enc from the start of data, if any.
+func TrimBOM(data []byte, enc Encoding) []byte {
+	switch enc {
+	case UTF8:
+		return bytes.TrimPrefix(data, bomUTF8)
+	case UTF16LE:
+		return bytes.TrimPrefix(data, bomUTF16LE)
+	case UTF16BE:
+		return bytes.TrimPrefix(data, bomUTF16BE)
+	}
+	return data
+}
+
+// Detect sniffs the encoding of data, returning it along with the length of
+// any byte order mark. Without a BOM it falls back to heuristics: NUL-byte
+// distribution for UTF-16, then valid UTF-8, then Latin-1. UTF-16 goes first
+// because NUL bytes are valid UTF-8, so ASCII text in UTF-16 would pass.
+func Detect(data []byte) (Encoding, int) {
+	switch {
+	case bytes.HasPrefix(data, bomUTF8):
+		return UTF8, len(bomUTF8)
+	case bytes.HasPrefix(data, bomUTF16LE):
+		return UTF16LE, len(bomUTF16LE)
+	case bytes.HasPrefix(data, bomUTF16BE):
+		return UTF16BE, len(bomUTF16BE)
+	}
+
+	if enc, ok := detectUTF16(data); ok {
+		return enc, 0
+	}
+	if utf8.Valid(data) {
+		return UTF8, 0
+	}
+	return Latin1, 0
+}
+
+// detectUTF16 guesses UTF-16 without a BOM from where the NUL bytes fall:
+// mostly-ASCII text has one NUL in every pair, on the high byte.
+func detectUTF16(data []byte) (Encoding, bool) {
+	if len(data) < 2 || len(data)%2 != 0 {
+		return "", false
+	}
+	var evenNUL, oddNUL int
+	for i := 0; i < len(data); i += 2 {
+		if data[i] == 0 {
+			evenNUL++
+		}
+		if data[i+1] == 0 {
+			oddNUL++
+		}
+	}
+	pairs := len(data) / 2
+	switch {
+	case oddNUL*2 > pairs && evenNUL*10 < pairs:
+		return UTF16LE, true
+	case evenNUL*2 > pairs && oddNUL*10 < pairs:
+		return UTF16BE, true
+	}
+	return "", false
+}
+
+// ToUTF8 detects the encoding of data and transcodes it to UTF-8, dropping
+// any byte order mark.
+func ToUTF8(data []byte) (string, Encoding, error) {
+	enc, bom := Detect(data)
+	s, err := Decode(data[bom:], enc)
+	return s, enc, err
+}
+
+func Decode(data []byte, enc Encoding) (string, error) {
+	switch enc {
+	case UTF8:
+		return string(data), nil
+	case UTF16LE, UTF16BE:
+		return decodeUTF16(data, enc == UTF16BE)
+	case Latin1:
+		var b strings.Builder
+		b.Grow(len(data))
+		for _, c := range data {
+			b.WriteRune(rune(c))
+		}
+		return b.String(), nil
+	default:
+		return "", fmt.Errorf("unsupported encoding %q", enc)
+	}
+}
+
+func decodeUTF16(data []byte, bigEndian bool) (string, error) {
+	if len(data)%2 != 0 {
+		return "", fmt.Errorf("utf-16 input has odd length %d", len(data))
+	}
+
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		lo, hi := data[2*i], data[2*i+1]
+		if bigEndian {
+			lo, hi = hi, lo
+		}
+		units[i] = uint16(lo) | uint16(hi)<<8
+	}
+	return string(utf16.Decode(units)), nil
+}
@@ -0,0 +1,58 @@
+package charset
+
+import (
+	"testing"
+	"unicode/utf16"
+)
+
+func utf16LE(s string) []byte {
+	var b []byte
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = append(b, byte(u), byte(u>>8))
+	}
+	return b
+}
+
+func TestDetect(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		enc  Encoding
+		bom  int
+	}{
+		{"utf-8", []byte("héllo\nworld\n"), UTF8, 0},
+		{"utf-8 bom", append([]byte{0xEF, 0xBB, 0xBF}, "x\n"...), UTF8, 3},
+		{"utf-16le bom", append([]byte{0xFF, 0xFE}, utf16LE("a\n")...), UTF16LE, 2},
+		{"utf-16be bom", []byte{0xFE, 0xFF, 0, 'a', 0, '\n'}, UTF16BE, 2},
+		{"utf-16le no bom", []byte{'a', 0, 'b', 0, '\n', 0}, UTF16LE, 0},
+		{"utf-16be no bom", []byte{0, 'a', 0, 'b', 0, '\n'}, UTF16BE, 0},
+		{"latin-1", []byte{'c', 'a', 'f', 0xE9}, Latin1, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enc, bom := Detect(tt.data)
+			if enc != tt.enc || bom != tt.bom {
+				t.Errorf("Detect = %s, %d; want %s, %d", enc, bom, tt.enc, tt.bom)
+			}
+		})
+	}
+}
+
+func TestToUTF8(t *testing.T) {
+	want := "package main\r\n// héllo\n"
+	got, enc, err := ToUTF8(append([]byte{0xFF, 0xFE}, utf16LE(want)...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enc != UTF16LE || got != want {
+		t.Errorf("ToUTF8 = %q (%s), want %q (%s)", got, enc, want, UTF16LE)
+	}
+
+	got, enc, err = ToUTF8([]byte(want))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enc != UTF8 || got != want {
+		t.Errorf("ToUTF8 = %q (%s), want %q (%s)", got, enc, want, UTF8)
+	}
+}
@@ -4,8 +4,30 @@ import (
 	"fmt"
 	"os/exec"
 	"runtime"
+	"strings"
+
+	"goscaffold/pkg/charset"
 )
 
+// run executes a clipboard command and returns its stdout. Tests may
+// replace it.
+var run = func(name string, args ...string) ([]byte, error) {
+	return exec.Command(name, args...).Output()
+}
+
+// Tools returns the commands Read may use on this platform, in the order
+// it tries them. Any one of them is enough.
+func Tools() []string {
+	switch runtime.GOOS {
+	case "windows":
+		return []string{"powershell"}
+	case "darwin":
+		return []string{"pbpaste"}
+	default:
+		return []string{"xclip", "xsel"}
+	}
+}
+
 func Read() (string, error) {
 	switch runtime.GOOS {
 	case "windows":
@@ -17,18 +39,32 @@ func Read() (string, error) {
 	}
 }
 
+// psGetClipboard forces UTF-8 output, since PowerShell otherwise writes in
+// the console's OEM codepage, and reads the clipboard as one string with -Raw
+// rather than as lines.
+const psGetClipboard = "[Console]::OutputEncoding = [System.Text.Encoding]::UTF8; Get-Clipboard -Raw"
+
 func readWindows() (string, error) {
-	cmd := exec.Command("powershell", "-command", "Get-Clipboard")
-	out, err := cmd.Output()
+	out, err := run("powershell", "-NoProfile", "-NonInteractive", "-Command", psGetClipboard)
 	if err != nil {
 		return "", fmt.Errorf("windows clipboard: %w", err)
 	}
-	return string(out), nil
+
+	// Older hosts may still emit UTF-16 or a BOM; ToUTF8 handles both.
+	text, _, err := charset.ToUTF8(out)
+	if err != nil {
+		return "", fmt.Errorf("windows clipboard: %w", err)
+	}
+
+	// PowerShell terminates its output with a newline of its own.
+	if t, ok := strings.CutSuffix(text, "\r\n"); ok {
+		return t, nil
+	}
+	return strings.TrimSuffix(text, "\n"), nil
 }
 
 func readMac() (string, error) {
-	cmd := exec.Command("pbpaste")
-	out, err := cmd.Output()
+	out, err := run("pbpaste")
 	if err != nil {
 		return "", fmt.Errorf("mac clipboard: %w", err)
 	}
@@ -36,11 +72,9 @@ func readMac() (string, error) {
 }
 
 func readLinux() (string, error) {
-	cmd := exec.Command("xclip", "-selection", "clipboard", "-o")
-	out, err := cmd.Output()
+	out, err := run("xclip", "-selection", "clipboard", "-o")
 	if err != nil {
-		cmd = exec.Command("xsel", "--clipboard", "--output")
-		out, err = cmd.Output()
+		out, err = run("xsel", "--clipboard", "--output")
 		if err != nil {
 			return "", fmt.Errorf("linux clipboard: %w", err)
 		}
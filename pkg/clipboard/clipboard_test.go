@@ -0,0 +1,69 @@
+package clipboard
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// fakeRun replaces run for the test, returning out for every command.
+func fakeRun(t *testing.T, out []byte, err error) *[][]string {
+	t.Helper()
+	var calls [][]string
+	old := run
+	run = func(name string, args ...string) ([]byte, error) {
+		calls = append(calls, append([]string{name}, args...))
+		return out, err
+	}
+	t.Cleanup(func() { run = old })
+	return &calls
+}
+
+func utf16LEWithBOM(s string) []byte {
+	b := []byte{0xFF, 0xFE}
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = append(b, byte(u), byte(u>>8))
+	}
+	return b
+}
+
+func TestReadWindows(t *testing.T) {
+	const text = "// héllo wörld ✓\r\nfunc main() {}\r\n"
+	tests := []struct {
+		name string
+		out  []byte
+	}{
+		{"utf-16le bom", utf16LEWithBOM(text + "\r\n")},
+		{"utf-8 bom", append([]byte{0xEF, 0xBB, 0xBF}, text+"\r\n"...)},
+		{"utf-8", []byte(text + "\r\n")},
+		{"utf-8 lf", []byte(text + "\n")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := fakeRun(t, tt.out, nil)
+
+			got, err := readWindows()
+			if err != nil {
+				t.Fatal(err)
+			}
+			// Only PowerShell's own trailing newline is dropped.
+			if got != text {
+				t.Errorf("readWindows = %q, want %q", got, text)
+			}
+			if len(*calls) != 1 || (*calls)[0][0] != "powershell" || !slices.Contains((*calls)[0], psGetClipboard) {
+				t.Errorf("ran %v", *calls)
+			}
+		})
+	}
+}
+
+func TestReadWindowsError(t *testing.T) {
+	fakeRun(t, nil, errors.New("not found"))
+
+	_, err := readWindows()
+	if err == nil || !strings.Contains(err.Error(), "windows clipboard") {
+		t.Errorf("readWindows error = %v", err)
+	}
+}
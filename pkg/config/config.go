@@ -1,13 +1,27 @@
 package config
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
 	"github.com/spf13/viper"
+
+	"goscaffold/pkg/backup"
 )
 
+// Themes are the accepted ui.theme values (see pkg/ui).
+var Themes = []string{"dark", "light", "none"}
+
 type Config struct {
 	Backup struct {
 		Enabled   bool   `mapstructure:"enabled"`
 		Retention string `mapstructure:"retention"`
+		Keep      int    `mapstructure:"keep"`
 		Path      string `mapstructure:"path"`
 	} `mapstructure:"backup"`
 
@@ -15,6 +29,11 @@ type Config struct {
 		AutoCommit    bool   `mapstructure:"auto_commit"`
 		DefaultBranch string `mapstructure:"default_branch"`
 		AutoInit      bool   `mapstructure:"auto_init"`
+		Remote        string `mapstructure:"remote"`
+		// CommitTemplate is a text/template for import commit messages.
+		CommitTemplate string `mapstructure:"commit_template"`
+		// CommitTrailers are "Key: value" lines appended to import commits.
+		CommitTrailers []string `mapstructure:"commit_trailers"`
 	} `mapstructure:"git"`
 
 	UI struct {
@@ -22,7 +41,21 @@ type Config struct {
 		ConfirmCreate bool   `mapstructure:"confirm_create"`
 	} `mapstructure:"ui"`
 
+	Import struct {
+		Concurrency int `mapstructure:"concurrency"`
+	} `mapstructure:"import"`
+
+	New struct {
+		TemplateOverrides string `mapstructure:"template_overrides"`
+		TemplatesDir      string `mapstructure:"templates_dir"`
+	} `mapstructure:"new"`
+
+	Parser struct {
+		UnnamedStrategy string `mapstructure:"unnamed_strategy"`
+	} `mapstructure:"parser"`
+
 	Validators []Validator `mapstructure:"validators"`
+	Formatters []Formatter `mapstructure:"formatters"`
 	Templates  []Template  `mapstructure:"templates"`
 }
 
@@ -30,18 +63,116 @@ type Validator struct {
 	Extension string   `mapstructure:"extension"`
 	Command   string   `mapstructure:"command"`
 	Args      []string `mapstructure:"args"`
+	Timeout   string   `mapstructure:"timeout"`
+	Strict    bool     `mapstructure:"strict"`
+	Stdin     bool     `mapstructure:"stdin"`
+
+	// Retries re-runs the command after a transient failure: one it could
+	// not be started or was killed for, or an exit code in RetryExitCodes.
+	Retries        int   `mapstructure:"retries"`
+	RetryExitCodes []int `mapstructure:"retry_exit_codes"`
+}
+
+// Formatter runs Command with Args and the written file's path appended;
+// it is expected to rewrite the file in place.
+type Formatter struct {
+	Extension string   `mapstructure:"extension"`
+	Command   string   `mapstructure:"command"`
+	Args      []string `mapstructure:"args"`
+	Timeout   string   `mapstructure:"timeout"`
 }
 
 type Template struct {
 	Name        string                 `mapstructure:"name"`
 	Description string                 `mapstructure:"description"`
 	Structure   map[string]interface{} `mapstructure:"structure"`
+
+	// Dir, when set, is a directory of template files used instead of
+	// Structure (see pkg/template).
+	Dir string `mapstructure:"dir"`
 }
 
+// Load unmarshals the config from viper. Environment variables ($VAR or
+// ${VAR}) are expanded in backup.path, new.template_overrides,
+// new.templates_dir, template dirs and validator and formatter commands
+// only; other fields, including validator args and template contents, are
+// taken literally since "$" is meaningful there.
 func Load() (*Config, error) {
 	var cfg Config
 	if err := viper.Unmarshal(&cfg); err != nil {
 		return nil, err
 	}
+
+	cfg.Backup.Path = os.ExpandEnv(cfg.Backup.Path)
+	cfg.New.TemplateOverrides = os.ExpandEnv(cfg.New.TemplateOverrides)
+	cfg.New.TemplatesDir = os.ExpandEnv(cfg.New.TemplatesDir)
+	for i := range cfg.Validators {
+		cfg.Validators[i].Command = os.ExpandEnv(cfg.Validators[i].Command)
+	}
+	for i := range cfg.Formatters {
+		cfg.Formatters[i].Command = os.ExpandEnv(cfg.Formatters[i].Command)
+	}
+	for i := range cfg.Templates {
+		cfg.Templates[i].Dir = os.ExpandEnv(cfg.Templates[i].Dir)
+	}
 	return &cfg, nil
 }
+
+// Validate checks values that would otherwise only fail when used. Each
+// error names the offending field.
+func (c *Config) Validate() error {
+	var errs []error
+
+	if _, err := backup.ParseRetention(c.Backup.Retention); err != nil {
+		errs = append(errs, fmt.Errorf("backup.retention: %w", err))
+	}
+
+	if _, err := template.New("commit").Parse(c.Git.CommitTemplate); err != nil {
+		errs = append(errs, fmt.Errorf("git.commit_template: %w", err))
+	}
+
+	for i, t := range c.Git.CommitTrailers {
+		if key, _, ok := strings.Cut(t, ": "); !ok || key == "" || strings.ContainsAny(key, " \n") {
+			errs = append(errs, fmt.Errorf("git.commit_trailers[%d]: %q is not a \"Key: value\" trailer", i, t))
+		}
+	}
+
+	if c.UI.Theme != "" && !slices.Contains(Themes, c.UI.Theme) {
+		errs = append(errs, fmt.Errorf("ui.theme: unknown theme %q (want one of %v)", c.UI.Theme, Themes))
+	}
+
+	for i, v := range c.Validators {
+		field := fmt.Sprintf("validators[%d]", i)
+		if v.Extension == "" {
+			errs = append(errs, fmt.Errorf("%s.extension: must not be empty", field))
+		}
+		if v.Command == "" {
+			errs = append(errs, fmt.Errorf("%s.command: must not be empty", field))
+		}
+		if v.Timeout != "" {
+			if _, err := time.ParseDuration(v.Timeout); err != nil {
+				errs = append(errs, fmt.Errorf("%s.timeout: %w", field, err))
+			}
+		}
+		if v.Retries < 0 {
+			errs = append(errs, fmt.Errorf("%s.retries: must not be negative", field))
+		}
+	}
+
+	for i, f := range c.Formatters {
+		field := fmt.Sprintf("formatters[%d]", i)
+		if f.Extension == "" {
+			errs = append(errs, fmt.Errorf("%s.extension: must not be empty", field))
+		}
+		if f.Command == "" {
+			errs = append(errs, fmt.Errorf("%s.command: must not be empty", field))
+		}
+		if f.Timeout != "" {
+			if _, err := time.ParseDuration(f.Timeout); err != nil {
+				errs = append(errs, fmt.Errorf("%s.timeout: %w", field, err))
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,137 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// setConfig replaces the viper config with values for the test.
+func setConfig(t *testing.T, values map[string]any) {
+	t.Helper()
+	viper.Reset()
+	t.Cleanup(viper.Reset)
+	for k, v := range values {
+		viper.Set(k, v)
+	}
+}
+
+func TestLoadExpandsEnv(t *testing.T) {
+	t.Setenv("GS_TEST_HOME", "/home/tester")
+	setConfig(t, map[string]any{
+		"backup.path":            "$GS_TEST_HOME/backups",
+		"new.template_overrides": "${GS_TEST_HOME}/overrides",
+		"new.templates_dir":      "$GS_TEST_HOME/templates",
+		"git.commit_template":    "import $GS_TEST_HOME",
+		"validators": []map[string]any{
+			{"extension": ".go", "command": "$GS_TEST_HOME/bin/lint", "args": []string{"$GS_TEST_HOME"}},
+		},
+		"formatters": []map[string]any{
+			{"extension": ".go", "command": "$GS_TEST_HOME/bin/fmt"},
+		},
+		"templates": []map[string]any{
+			{"name": "svc", "dir": "$GS_TEST_HOME/svc"},
+		},
+	})
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expanded := map[string]string{
+		"backup.path":            cfg.Backup.Path,
+		"new.template_overrides": cfg.New.TemplateOverrides,
+		"new.templates_dir":      cfg.New.TemplatesDir,
+		"validators[0].command":  cfg.Validators[0].Command,
+		"formatters[0].command":  cfg.Formatters[0].Command,
+		"templates[0].dir":       cfg.Templates[0].Dir,
+	}
+	want := map[string]string{
+		"backup.path":            "/home/tester/backups",
+		"new.template_overrides": "/home/tester/overrides",
+		"new.templates_dir":      "/home/tester/templates",
+		"validators[0].command":  "/home/tester/bin/lint",
+		"formatters[0].command":  "/home/tester/bin/fmt",
+		"templates[0].dir":       "/home/tester/svc",
+	}
+	for k, got := range expanded {
+		if got != want[k] {
+			t.Errorf("%s = %q, want %q", k, got, want[k])
+		}
+	}
+
+	// Everything else is taken literally.
+	if got := cfg.Git.CommitTemplate; got != "import $GS_TEST_HOME" {
+		t.Errorf("git.commit_template = %q, want it unexpanded", got)
+	}
+	if got := cfg.Validators[0].Args[0]; got != "$GS_TEST_HOME" {
+		t.Errorf("validators[0].args[0] = %q, want it unexpanded", got)
+	}
+}
+
+// validConfig returns a config that passes Validate.
+func validConfig() *Config {
+	c := &Config{}
+	c.Backup.Retention = "7d"
+	c.Git.CommitTemplate = "import {{.FileCount}} files"
+	c.Git.CommitTrailers = []string{"Generated-by: goscaffold"}
+	c.UI.Theme = "dark"
+	c.Validators = []Validator{{Extension: ".go", Command: "gofmt", Timeout: "5s"}}
+	c.Formatters = []Formatter{{Extension: ".go", Command: "gofmt", Timeout: "5s"}}
+	return c
+}
+
+func TestValidate(t *testing.T) {
+	if err := validConfig().Validate(); err != nil {
+		t.Fatalf("valid config: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		field  string
+	}{
+		{"retention", func(c *Config) { c.Backup.Retention = "7days" }, "backup.retention"},
+		{"commit template", func(c *Config) { c.Git.CommitTemplate = "{{.FileCount" }, "git.commit_template"},
+		{"trailer without separator", func(c *Config) { c.Git.CommitTrailers = []string{"ok: 1", "no separator"} }, "git.commit_trailers[1]"},
+		{"trailer key with space", func(c *Config) { c.Git.CommitTrailers = []string{"Made by: me"} }, "git.commit_trailers[0]"},
+		{"theme", func(c *Config) { c.UI.Theme = "neon" }, "ui.theme"},
+		{"validator extension", func(c *Config) { c.Validators[0].Extension = "" }, "validators[0].extension"},
+		{"validator command", func(c *Config) { c.Validators[0].Command = "" }, "validators[0].command"},
+		{"validator timeout", func(c *Config) { c.Validators[0].Timeout = "soon" }, "validators[0].timeout"},
+		{"validator retries", func(c *Config) { c.Validators[0].Retries = -1 }, "validators[0].retries"},
+		{"formatter extension", func(c *Config) { c.Formatters[0].Extension = "" }, "formatters[0].extension"},
+		{"formatter command", func(c *Config) { c.Formatters[0].Command = "" }, "formatters[0].command"},
+		{"formatter timeout", func(c *Config) { c.Formatters[0].Timeout = "5 s" }, "formatters[0].timeout"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := validConfig()
+			tt.modify(c)
+
+			err := c.Validate()
+			if err == nil || !strings.HasPrefix(err.Error(), tt.field+":") {
+				t.Errorf("Validate = %v, want an error for %s", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestValidateReportsEveryField(t *testing.T) {
+	c := validConfig()
+	c.Backup.Retention = "x"
+	c.UI.Theme = "x"
+	c.Validators[0].Command = ""
+
+	err := c.Validate()
+	if err == nil {
+		t.Fatal("Validate succeeded")
+	}
+	for _, field := range []string{"backup.retention", "ui.theme", "validators[0].command"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("error %q does not name %s", err, field)
+		}
+	}
+}
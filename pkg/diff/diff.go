@@ -0,0 +1,217 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	contextLines = 3
+
+	// maxCells bounds the LCS table; larger inputs are shown as a full
+	// replacement rather than spending quadratic memory.
+	maxCells = 4_000_000
+)
+
+const (
+	colorRed   = "\x1b[31m"
+	colorGreen = "\x1b[32m"
+	colorCyan  = "\x1b[36m"
+	colorReset = "\x1b[0m"
+)
+
+type opKind byte
+
+const (
+	opEqual  opKind = ' '
+	opDelete opKind = '-'
+	opInsert opKind = '+'
+)
+
+type op struct {
+	kind opKind
+	line string
+}
+
+// Unified returns a unified diff from old to new labelled with path, or ""
+// when the contents are identical.
+func Unified(old, new, path string) string {
+	if old == new {
+		return ""
+	}
+
+	ops := lineOps(splitLines(old), splitLines(new))
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", path, path)
+	for _, h := range hunks(ops) {
+		writeHunk(&b, ops, h)
+	}
+	return b.String()
+}
+
+// Colorize wraps added, removed and hunk header lines of a unified diff in
+// ANSI colors.
+func Colorize(diff string) string {
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			b.WriteString(line)
+		case strings.HasPrefix(line, "+"):
+			b.WriteString(colorGreen + strings.TrimSuffix(line, "\n") + colorReset + "\n")
+		case strings.HasPrefix(line, "-"):
+			b.WriteString(colorRed + strings.TrimSuffix(line, "\n") + colorReset + "\n")
+		case strings.HasPrefix(line, "@@"):
+			b.WriteString(colorCyan + strings.TrimSuffix(line, "\n") + colorReset + "\n")
+		default:
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
+
+// Matches aligns a with b by longest common subsequence, returning for each
+// line of a the index of its matching line in b, or -1.
+func Matches(a, b []string) []int {
+	m := make([]int, len(a))
+	i, j := 0, 0
+	for _, o := range lineOps(a, b) {
+		switch o.kind {
+		case opEqual:
+			m[i] = j
+			i++
+			j++
+		case opDelete:
+			m[i] = -1
+			i++
+		case opInsert:
+			j++
+		}
+	}
+	return m
+}
+
+// SplitLines splits s into lines, ignoring a single trailing newline.
+func SplitLines(s string) []string {
+	return splitLines(s)
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// lineOps computes an edit script from a to b via longest common subsequence.
+func lineOps(a, b []string) []op {
+	if (len(a)+1)*(len(b)+1) > maxCells {
+		ops := make([]op, 0, len(a)+len(b))
+		for _, l := range a {
+			ops = append(ops, op{opDelete, l})
+		}
+		for _, l := range b {
+			ops = append(ops, op{opInsert, l})
+		}
+		return ops
+	}
+
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []op
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, op{opEqual, a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, op{opDelete, a[i]})
+			i++
+		default:
+			ops = append(ops, op{opInsert, b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, op{opDelete, a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, op{opInsert, b[j]})
+	}
+	return ops
+}
+
+// hunk is a half-open range of ops.
+type hunk struct{ start, end int }
+
+func hunks(ops []op) []hunk {
+	var hs []hunk
+	for i, o := range ops {
+		if o.kind == opEqual {
+			continue
+		}
+		start := max(i-contextLines, 0)
+		end := min(i+contextLines+1, len(ops))
+		if n := len(hs); n > 0 && start <= hs[n-1].end {
+			hs[n-1].end = max(hs[n-1].end, end)
+			continue
+		}
+		hs = append(hs, hunk{start, end})
+	}
+	return hs
+}
+
+func writeHunk(b *strings.Builder, ops []op, h hunk) {
+	// Line numbers of the hunk start in each file.
+	oldLine, newLine := 1, 1
+	for _, o := range ops[:h.start] {
+		if o.kind != opInsert {
+			oldLine++
+		}
+		if o.kind != opDelete {
+			newLine++
+		}
+	}
+
+	var oldCount, newCount int
+	for _, o := range ops[h.start:h.end] {
+		if o.kind != opInsert {
+			oldCount++
+		}
+		if o.kind != opDelete {
+			newCount++
+		}
+	}
+
+	fmt.Fprintf(b, "@@ -%s +%s @@\n", span(oldLine, oldCount), span(newLine, newCount))
+	for _, o := range ops[h.start:h.end] {
+		b.WriteByte(byte(o.kind))
+		b.WriteString(o.line)
+		b.WriteByte('\n')
+	}
+}
+
+func span(start, count int) string {
+	if count == 0 {
+		start--
+	}
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
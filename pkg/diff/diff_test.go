@@ -0,0 +1,45 @@
+package diff
+
+import "testing"
+
+func TestUnifiedRoundTrip(t *testing.T) {
+	tests := []struct {
+		name, old, new string
+	}{
+		{"change", "a\nb\nc\nd\ne\nf\ng\nh\ni\nj\n", "a\nB\nc\nd\ne\nf\ng\nh\nI\nj\n"},
+		{"insert", "a\nb\n", "a\nnew\nb\n"},
+		{"remove", "a\nb\nc\n", "a\nc\n"},
+		{"append", "a\n", "a\nb\nc\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := Unified(tt.old, tt.new, "x.txt")
+			if !IsPatch(u) {
+				t.Fatalf("Unified output is not a patch:\n%s", u)
+			}
+			got, err := parseOne(t, u).Apply(tt.old)
+			if err != nil {
+				t.Fatalf("%v\n%s", err, u)
+			}
+			if got != tt.new {
+				t.Errorf("Apply(Unified) = %q, want %q", got, tt.new)
+			}
+		})
+	}
+}
+
+func TestUnifiedIdentical(t *testing.T) {
+	if u := Unified("a\nb\n", "a\nb\n", "x.txt"); u != "" {
+		t.Errorf("Unified of identical content = %q, want empty", u)
+	}
+}
+
+func TestMatches(t *testing.T) {
+	got := Matches([]string{"a", "x", "b", "c"}, []string{"a", "b", "y", "c"})
+	want := []int{0, -1, 1, 3}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Matches = %v, want %v", got, want)
+		}
+	}
+}
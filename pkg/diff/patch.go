@@ -0,0 +1,202 @@
+package diff
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DevNull is the path a patch uses for the missing side of a created or
+// deleted file.
+const DevNull = "/dev/null"
+
+// FilePatch is the part of a unified diff that changes one file.
+type FilePatch struct {
+	OldPath string
+	NewPath string
+	Hunks   []Hunk
+}
+
+// Hunk is one @@ section. Lines keep their ' ', '-' or '+' prefix.
+type Hunk struct {
+	OldStart int
+	Lines    []string
+}
+
+// Created reports whether the patch creates its file from nothing.
+func (p FilePatch) Created() bool {
+	return p.OldPath == DevNull
+}
+
+// Deleted reports whether the patch deletes its file.
+func (p FilePatch) Deleted() bool {
+	return p.NewPath == DevNull
+}
+
+// IsPatch reports whether text contains a unified diff file header.
+func IsPatch(text string) bool {
+	lines := strings.Split(text, "\n")
+	for i := range lines {
+		if isFileHeader(lines, i) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParsePatch splits a unified diff (git or plain diff -u output) into file
+// patches, stripping the a/ and b/ prefixes git adds. Hunk line counts are
+// not trusted, since hand-edited and generated diffs often get them wrong; a
+// hunk runs until the next hunk or file header.
+func ParsePatch(text string) ([]FilePatch, error) {
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+
+	var (
+		patches []FilePatch
+		cur     *FilePatch
+		hunk    *Hunk
+	)
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case isFileHeader(lines, i):
+			patches = append(patches, FilePatch{
+				OldPath: headerPath(line, "--- ", "a/"),
+				NewPath: headerPath(lines[i+1], "+++ ", "b/"),
+			})
+			cur, hunk = &patches[len(patches)-1], nil
+			i++
+		case strings.HasPrefix(line, "@@"):
+			if cur == nil {
+				return nil, fmt.Errorf("line %d: hunk before file header", i+1)
+			}
+			start, err := hunkStart(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+			cur.Hunks = append(cur.Hunks, Hunk{OldStart: start})
+			hunk = &cur.Hunks[len(cur.Hunks)-1]
+		case hunk == nil:
+			// diff --git, index and mode lines, or prose between patches.
+		case line == "":
+			// Some tools strip the space from empty context lines.
+			hunk.Lines = append(hunk.Lines, " ")
+		case line[0] == ' ' || line[0] == '-' || line[0] == '+':
+			hunk.Lines = append(hunk.Lines, line)
+		case strings.HasPrefix(line, `\ `):
+			// "\ No newline at end of file"; output always ends in one.
+		default:
+			hunk = nil
+		}
+	}
+
+	if len(patches) == 0 {
+		return nil, errors.New("no file headers (--- / +++) found")
+	}
+	return patches, nil
+}
+
+// Apply applies the patch's hunks to old and returns the new content, which
+// always ends in a newline. A hunk whose context and removed lines are not
+// found at, or failing that anywhere after, the previous hunk is an error.
+func (p FilePatch) Apply(old string) (string, error) {
+	if p.Deleted() {
+		return "", errors.New("deleting files is not supported")
+	}
+
+	src := splitLines(old)
+	var out []string
+	pos := 0
+	for n, h := range p.Hunks {
+		var want, repl []string
+		for _, l := range h.Lines {
+			switch l[0] {
+			case ' ':
+				want = append(want, l[1:])
+				repl = append(repl, l[1:])
+			case '-':
+				want = append(want, l[1:])
+			case '+':
+				repl = append(repl, l[1:])
+			}
+		}
+
+		at := findLines(src, want, pos, h.OldStart-1)
+		if at < 0 {
+			return "", fmt.Errorf("hunk %d (@@ -%d) does not apply", n+1, h.OldStart)
+		}
+		out = append(out, src[pos:at]...)
+		out = append(out, repl...)
+		pos = at + len(want)
+	}
+	out = append(out, src[pos:]...)
+
+	if len(out) == 0 {
+		return "", nil
+	}
+	return strings.Join(out, "\n") + "\n", nil
+}
+
+// findLines returns the index at or after from where want occurs in src,
+// preferring hint and then the nearest match to it, or -1.
+func findLines(src, want []string, from, hint int) int {
+	matches := func(at int) bool {
+		if at < from || at+len(want) > len(src) {
+			return false
+		}
+		for i, w := range want {
+			if src[at+i] != w {
+				return false
+			}
+		}
+		return true
+	}
+
+	if hint < from {
+		hint = from
+	}
+	for d := 0; hint-d >= from || hint+d <= len(src); d++ {
+		if matches(hint - d) {
+			return hint - d
+		}
+		if matches(hint + d) {
+			return hint + d
+		}
+	}
+	return -1
+}
+
+func isFileHeader(lines []string, i int) bool {
+	return strings.HasPrefix(lines[i], "--- ") &&
+		i+1 < len(lines) &&
+		strings.HasPrefix(lines[i+1], "+++ ")
+}
+
+// headerPath extracts the path from a ---/+++ line, dropping a trailing
+// timestamp and the git side prefix.
+func headerPath(line, marker, side string) string {
+	path := strings.TrimPrefix(line, marker)
+	if tab := strings.IndexByte(path, '\t'); tab >= 0 {
+		path = path[:tab]
+	}
+	path = strings.TrimSpace(path)
+	if path == DevNull {
+		return path
+	}
+	return strings.TrimPrefix(path, side)
+}
+
+// hunkStart parses the old start line from "@@ -l,s +l,s @@".
+func hunkStart(line string) (int, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || !strings.HasPrefix(fields[1], "-") {
+		return 0, fmt.Errorf("malformed hunk header %q", line)
+	}
+	start, _, _ := strings.Cut(fields[1][1:], ",")
+	n, err := strconv.Atoi(start)
+	if err != nil {
+		return 0, fmt.Errorf("malformed hunk header %q", line)
+	}
+	return n, nil
+}
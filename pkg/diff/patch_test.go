@@ -0,0 +1,157 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestIsPatch(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"--- a/x.go\n+++ b/x.go\n@@ -1 +1 @@\n-a\n+b\n", true},
+		{"prose first\n--- x.go\t2024-01-01\n+++ x.go\t2024-01-02\n", true},
+		{"---\npath: x.go\n---\ncode\n", false},
+		{"--- only the old side\n", false},
+		{"plain text\n", false},
+	}
+	for _, tt := range tests {
+		if got := IsPatch(tt.text); got != tt.want {
+			t.Errorf("IsPatch(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name, old, patch, want string
+	}{
+		{
+			name:  "at the stated line",
+			old:   "a\nb\nc\n",
+			patch: "@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+			want:  "a\nB\nc\n",
+		},
+		{
+			name:  "offset hunk",
+			old:   "x\ny\nz\na\nb\nc\n",
+			patch: "@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+			want:  "x\ny\nz\na\nB\nc\n",
+		},
+		{
+			name:  "two hunks",
+			old:   "a\nb\nc\nd\ne\nf\ng\n",
+			patch: "@@ -1,2 +1,2 @@\n-a\n+A\n b\n@@ -6,2 +6,2 @@\n f\n-g\n+G\n",
+			want:  "A\nb\nc\nd\ne\nf\nG\n",
+		},
+		{
+			name:  "stripped empty context line",
+			old:   "a\n\nb\n",
+			patch: "@@ -1,3 +1,3 @@\n a\n\n-b\n+B\n",
+			want:  "a\n\nB\n",
+		},
+		{
+			name:  "old file without trailing newline",
+			old:   "a\nb",
+			patch: "@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+B\n\\ No newline at end of file\n",
+			want:  "a\nB\n",
+		},
+		{
+			name:  "new file",
+			old:   "",
+			patch: "@@ -0,0 +1,2 @@\n+a\n+b\n",
+			want:  "a\nb\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parseOne(t, "--- a/x.txt\n+++ b/x.txt\n"+tt.patch)
+			got, err := p.Apply(tt.old)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Apply = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyRejected(t *testing.T) {
+	tests := []struct {
+		name, old, patch, want string
+	}{
+		{
+			name:  "context mismatch",
+			old:   "a\nb\nc\n",
+			patch: "@@ -1,3 +1,3 @@\n a\n-x\n+B\n c\n",
+			want:  "hunk 1 (@@ -1) does not apply",
+		},
+		{
+			name:  "second hunk before the first",
+			old:   "a\nb\nc\nd\n",
+			patch: "@@ -3,2 +3,2 @@\n-c\n+C\n d\n@@ -1,1 +1,1 @@\n-a\n+A\n",
+			want:  "hunk 2 (@@ -1) does not apply",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parseOne(t, "--- a/x.txt\n+++ b/x.txt\n"+tt.patch)
+			_, err := p.Apply(tt.old)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Apply error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePatchPaths(t *testing.T) {
+	text := "diff --git a/old.go b/new.go\n--- a/old.go\n+++ b/new.go\n@@ -1 +1 @@\n-a\n+b\n" +
+		"--- /dev/null\n+++ b/made.go\n@@ -0,0 +1 @@\n+x\n" +
+		"--- a/gone.go\n+++ /dev/null\n@@ -1 +0,0 @@\n-x\n"
+
+	patches, err := ParsePatch(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patches) != 3 {
+		t.Fatalf("got %d patches, want 3", len(patches))
+	}
+	if p := patches[0]; p.OldPath != "old.go" || p.NewPath != "new.go" || len(p.Hunks) != 1 {
+		t.Errorf("patch 1 = %+v", p)
+	}
+	if !patches[1].Created() || patches[1].NewPath != "made.go" {
+		t.Errorf("patch 2 = %+v, want made.go created", patches[1])
+	}
+	if !patches[2].Deleted() {
+		t.Errorf("patch 3 = %+v, want gone.go deleted", patches[2])
+	}
+	if _, err := patches[2].Apply("x\n"); err == nil {
+		t.Error("Apply of a deletion succeeded")
+	}
+}
+
+func TestParsePatchErrors(t *testing.T) {
+	for _, text := range []string{
+		"no headers here\n",
+		"@@ -1 +1 @@\n-a\n+b\n",
+		"--- a/x\n+++ b/x\n@@ nonsense @@\n",
+	} {
+		if _, err := ParsePatch(text); err == nil {
+			t.Errorf("ParsePatch(%q) succeeded", text)
+		}
+	}
+}
+
+func parseOne(t *testing.T, text string) FilePatch {
+	t.Helper()
+	patches, err := ParsePatch(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(patches) != 1 {
+		t.Fatalf("got %d patches, want 1", len(patches))
+	}
+	return patches[0]
+}
@@ -0,0 +1,122 @@
+// Package events writes import progress as JSON lines for programs that
+// drive goscaffold, such as editor plugins. Every line is one object whose
+// "event" field is start, file, summary or end.
+package events
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"goscaffold/pkg/stats"
+)
+
+const (
+	EventStart   = "start"
+	EventFile    = "file"
+	EventSummary = "summary"
+	EventEnd     = "end"
+)
+
+// Start is emitted once the input is parsed, before anything is written.
+type Start struct {
+	Event string    `json:"event"`
+	Time  time.Time `json:"time"`
+	Files int       `json:"files"`
+}
+
+// File is emitted as each file's outcome is recorded. Action is one of the
+// stats.Action constants.
+type File struct {
+	Event      string `json:"event"`
+	Action     string `json:"action"`
+	Path       string `json:"path"`
+	NewPath    string `json:"newPath,omitempty"`
+	Bytes      int    `json:"bytes"`
+	BackupPath string `json:"backupPath,omitempty"`
+}
+
+// Summary carries the totals once processing has finished.
+type Summary struct {
+	Event       string `json:"event"`
+	Files       int    `json:"files"`
+	Bytes       int    `json:"bytes"`
+	Created     int    `json:"created"`
+	Overwritten int    `json:"overwritten"`
+	Unchanged   int    `json:"unchanged"`
+	Deleted     int    `json:"deleted"`
+	Renamed     int    `json:"renamed"`
+	Skipped     int    `json:"skipped"`
+	Failed      int    `json:"failed"`
+	ElapsedMS   int64  `json:"elapsedMs"`
+}
+
+// End is the last event of an import; Error is set when it failed.
+type End struct {
+	Event string    `json:"event"`
+	Time  time.Time `json:"time"`
+	OK    bool      `json:"ok"`
+	Error string    `json:"error,omitempty"`
+}
+
+// Emitter writes events to w, one JSON object per line. It is safe for
+// concurrent use, and a nil *Emitter discards everything.
+type Emitter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func New(w io.Writer) *Emitter {
+	return &Emitter{enc: json.NewEncoder(w)}
+}
+
+func (e *Emitter) emit(v any) {
+	if e == nil {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	// Events are best effort; a closed stderr must not fail the import.
+	_ = e.enc.Encode(v)
+}
+
+func (e *Emitter) Start(files int) {
+	e.emit(Start{Event: EventStart, Time: time.Now(), Files: files})
+}
+
+func (e *Emitter) File(f stats.FileStat) {
+	e.emit(File{
+		Event:      EventFile,
+		Action:     f.Action,
+		Path:       f.Path,
+		NewPath:    f.NewPath,
+		Bytes:      f.Bytes,
+		BackupPath: f.BackupPath,
+	})
+}
+
+// Summary must be called once s is no longer being updated.
+func (e *Emitter) Summary(s *stats.Stats) {
+	e.emit(Summary{
+		Event:       EventSummary,
+		Files:       s.TotalFiles,
+		Bytes:       s.TotalBytes,
+		Created:     s.Created,
+		Overwritten: s.Overwritten,
+		Unchanged:   s.Unchanged,
+		Deleted:     s.Deleted,
+		Renamed:     s.Renamed,
+		Skipped:     s.Skipped,
+		Failed:      s.Failed,
+		ElapsedMS:   s.Elapsed().Milliseconds(),
+	})
+}
+
+func (e *Emitter) End(err error) {
+	end := End{Event: EventEnd, Time: time.Now(), OK: err == nil}
+	if err != nil {
+		end.Error = err.Error()
+	}
+	e.emit(end)
+}
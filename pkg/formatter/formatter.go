@@ -0,0 +1,101 @@
+package formatter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"goscaffold/pkg/config"
+)
+
+const DefaultTimeout = 30 * time.Second
+
+var ErrNoFormatter = errors.New("no formatter configured")
+
+// Formatter rewrites a file in place, e.g. gofmt -w or prettier -w.
+type Formatter struct {
+	Extension string
+	Command   string
+	Args      []string
+	Timeout   time.Duration
+}
+
+var (
+	mu       sync.RWMutex
+	registry = map[string]*Formatter{}
+)
+
+// Configure replaces the registered formatters with cfgs, keyed by
+// extension (with or without the leading dot).
+func Configure(cfgs []config.Formatter) error {
+	formatters := make(map[string]*Formatter, len(cfgs))
+	for _, c := range cfgs {
+		timeout := DefaultTimeout
+		if c.Timeout != "" {
+			d, err := time.ParseDuration(c.Timeout)
+			if err != nil {
+				return fmt.Errorf("formatter %s: invalid timeout %q: %w", c.Extension, c.Timeout, err)
+			}
+			timeout = d
+		}
+
+		ext := normalizeExt(c.Extension)
+		formatters[ext] = &Formatter{
+			Extension: ext,
+			Command:   c.Command,
+			Args:      c.Args,
+			Timeout:   timeout,
+		}
+	}
+
+	mu.Lock()
+	registry = formatters
+	mu.Unlock()
+	return nil
+}
+
+func Get(path string) (*Formatter, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if f, ok := registry[normalizeExt(filepath.Ext(path))]; ok {
+		return f, nil
+	}
+	return nil, ErrNoFormatter
+}
+
+func normalizeExt(ext string) string {
+	return "." + strings.TrimPrefix(strings.ToLower(ext), ".")
+}
+
+// Format runs the command with path appended to Args. The command is killed
+// when ctx is cancelled or the timeout elapses.
+func (f *Formatter) Format(ctx context.Context, path string) error {
+	runCtx, cancel := context.WithTimeout(ctx, f.Timeout)
+	defer cancel()
+
+	args := append(append([]string{}, f.Args...), path)
+	cmd := exec.CommandContext(runCtx, f.Command, args...)
+
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	cmd.Stderr = &out
+
+	err := cmd.Run()
+	switch {
+	case err == nil:
+		return nil
+	case ctx.Err() != nil:
+		return ctx.Err()
+	case errors.Is(runCtx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("%s timed out after %s", f.Command, f.Timeout)
+	default:
+		return fmt.Errorf("%s: %w: %s", f.Command, err, strings.TrimSpace(out.String()))
+	}
+}
@@ -0,0 +1,33 @@
+package fsutil
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"os"
+)
+
+// SameContent reports whether the file at path holds exactly data. The file
+// is hashed as a stream, and not read at all when its size differs.
+func SameContent(path string, data []byte) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+	if info.Size() != int64(len(data)) {
+		return false, nil
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return false, err
+	}
+	want := sha256.Sum256(data)
+	return bytes.Equal(h.Sum(nil), want[:]), nil
+}
@@ -0,0 +1,71 @@
+package fsutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteAtomic(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteAtomic(path, []byte("new"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "new" {
+		t.Errorf("content = %q, want new", data)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode = %v, want 0600", info.Mode().Perm())
+	}
+	assertEntries(t, dir, "main.go")
+}
+
+func TestWriteAtomicCleansUpOnError(t *testing.T) {
+	dir := t.TempDir()
+	// Renaming a file over a non-empty directory fails after the temp
+	// file has been written.
+	path := filepath.Join(dir, "target")
+	if err := os.MkdirAll(filepath.Join(path, "child"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteAtomic(path, []byte("data"), 0644); err == nil {
+		t.Fatal("WriteAtomic over a directory succeeded")
+	}
+	assertEntries(t, dir, "target")
+}
+
+// assertEntries fails unless dir holds exactly the named entries.
+func assertEntries(t *testing.T, dir string, want ...string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, e := range entries {
+		got = append(got, e.Name())
+	}
+	if len(got) != len(want) {
+		t.Fatalf("%s holds %v, want %v", dir, got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("%s holds %v, want %v", dir, got, want)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+var ErrNothingToCommit = errors.New("nothing to commit")
+
+// run executes git in dir and returns its trimmed stdout. On failure the
+// error carries git's stderr.
+func run(ctx context.Context, dir string, args ...string) (string, error) {
+	out, err := runRaw(ctx, dir, args...)
+	return strings.TrimSpace(out), err
+}
+
+func runRaw(ctx context.Context, dir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = strings.TrimSpace(stdout.String())
+		}
+		return "", fmt.Errorf("git %s: %w: %s", args[0], err, msg)
+	}
+	return stdout.String(), nil
+}
+
+// Commit stages paths and commits only those paths with msg. It returns
+// ErrNothingToCommit when none of them changed.
+func Commit(ctx context.Context, paths []string, msg string) error {
+	if len(paths) == 0 {
+		return ErrNothingToCommit
+	}
+
+	if _, err := run(ctx, "", append([]string{"add", "--"}, paths...)...); err != nil {
+		return err
+	}
+
+	// diff --quiet exits 1 when there are staged changes.
+	diff := append([]string{"diff", "--cached", "--quiet", "--"}, paths...)
+	if _, err := run(ctx, "", diff...); err == nil {
+		return ErrNothingToCommit
+	}
+
+	_, err := run(ctx, "", append([]string{"commit", "-m", msg, "--"}, paths...)...)
+	return err
+}
+
+// Version returns the installed git's version, e.g. "2.43.0".
+func Version(ctx context.Context) (string, error) {
+	out, err := run(ctx, "", "version")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimPrefix(out, "git version "), nil
+}
+
+// WithTrailers appends trailers such as "Generated-by: goscaffold" to msg,
+// separated from it by a blank line as git interpret-trailers expects.
+func WithTrailers(msg string, trailers []string) string {
+	if len(trailers) == 0 {
+		return msg
+	}
+	return strings.TrimRight(msg, "\n") + "\n\n" + strings.Join(trailers, "\n") + "\n"
+}
+
+// InitRepo initializes a repository at path on branch and commits its
+// current contents.
+func InitRepo(path, branch string) error {
+	ctx := context.Background()
+
+	if _, err := run(ctx, path, "init"); err != nil {
+		return err
+	}
+	if branch != "" {
+		if _, err := run(ctx, path, "symbolic-ref", "HEAD", "refs/heads/"+branch); err != nil {
+			return err
+		}
+	}
+	if _, err := run(ctx, path, "add", "-A"); err != nil {
+		return err
+	}
+	_, err := run(ctx, path, "commit", "-m", "Initial commit")
+	return err
+}
+
+func CurrentBranch(ctx context.Context) (string, error) {
+	return run(ctx, "", "rev-parse", "--abbrev-ref", "HEAD")
+}
+
+func BranchExists(ctx context.Context, name string) (bool, error) {
+	_, err := run(ctx, "", "rev-parse", "--verify", "--quiet", "refs/heads/"+name)
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// CreateBranch creates name from the current HEAD and checks it out.
+func CreateBranch(ctx context.Context, name string) error {
+	_, err := run(ctx, "", "checkout", "-b", name)
+	return err
+}
+
+func SwitchBranch(ctx context.Context, name string) error {
+	_, err := run(ctx, "", "checkout", name)
+	return err
+}
+
+// ResetBranch points name at the current HEAD, creating it if needed, and
+// checks it out.
+func ResetBranch(ctx context.Context, name string) error {
+	_, err := run(ctx, "", "checkout", "-B", name)
+	return err
+}
+
+func Push(ctx context.Context, remote, branch string) error {
+	_, err := run(ctx, "", "push", "-u", remote, branch)
+	return err
+}
+
+// DirtyFiles lists modified, staged and untracked files, relative to the
+// current directory.
+func DirtyFiles(ctx context.Context) ([]string, error) {
+	prefix, err := run(ctx, "", "rev-parse", "--show-prefix")
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := runRaw(ctx, "", "status", "--porcelain", "-z", "--untracked-files=all")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	fields := strings.Split(out, "\x00")
+	for i := 0; i < len(fields); i++ {
+		entry := fields[i]
+		if len(entry) < 4 {
+			continue
+		}
+		// Renames and copies are followed by their source path.
+		if entry[0] == 'R' || entry[0] == 'C' {
+			i++
+		}
+
+		rel, err := filepath.Rel(filepath.FromSlash(prefix), filepath.FromSlash(entry[3:]))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, rel)
+	}
+	return files, nil
+}
+
+func IsClean(ctx context.Context) (bool, error) {
+	files, err := DirtyFiles(ctx)
+	return len(files) == 0, err
+}
@@ -0,0 +1,123 @@
+package git
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testRepo creates an initialized repository on branch main, makes it the
+// working directory and isolates git from the user's configuration.
+func testRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "README.md"), "# demo\n")
+	if err := InitRepo(dir, "main"); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+	return dir
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func gitOut(t *testing.T, args ...string) string {
+	t.Helper()
+	out, err := run(context.Background(), "", args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestInitRepo(t *testing.T) {
+	testRepo(t)
+	ctx := context.Background()
+
+	branch, err := CurrentBranch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if branch != "main" {
+		t.Errorf("branch = %s, want main", branch)
+	}
+	if got := gitOut(t, "log", "--format=%s"); got != "Initial commit" {
+		t.Errorf("log = %q, want the initial commit", got)
+	}
+	if got := gitOut(t, "ls-files"); got != "README.md" {
+		t.Errorf("tracked files = %q, want README.md", got)
+	}
+	if clean, err := IsClean(ctx); err != nil || !clean {
+		t.Errorf("IsClean = %v, %v; want a clean tree", clean, err)
+	}
+}
+
+func TestCommit(t *testing.T) {
+	testRepo(t)
+	ctx := context.Background()
+
+	writeFile(t, "cmd/main.go", "package main\n")
+	writeFile(t, "other.txt", "not imported\n")
+
+	if err := Commit(ctx, []string{"cmd/main.go"}, "import files"); err != nil {
+		t.Fatal(err)
+	}
+	if got := gitOut(t, "log", "-1", "--format=%s"); got != "import files" {
+		t.Errorf("last commit = %q", got)
+	}
+	if got := gitOut(t, "show", "--name-only", "--format=", "HEAD"); got != "cmd/main.go" {
+		t.Errorf("committed %q, want only cmd/main.go", got)
+	}
+
+	// The unrelated file is left uncommitted.
+	dirty, err := DirtyFiles(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(dirty, ",") != "other.txt" {
+		t.Errorf("DirtyFiles = %v, want [other.txt]", dirty)
+	}
+}
+
+func TestCommitNothingToCommit(t *testing.T) {
+	testRepo(t)
+	ctx := context.Background()
+
+	if err := Commit(ctx, nil, "msg"); !errors.Is(err, ErrNothingToCommit) {
+		t.Errorf("Commit with no paths = %v, want ErrNothingToCommit", err)
+	}
+	if err := Commit(ctx, []string{"README.md"}, "msg"); !errors.Is(err, ErrNothingToCommit) {
+		t.Errorf("Commit of an unchanged file = %v, want ErrNothingToCommit", err)
+	}
+}
+
+func TestCommitError(t *testing.T) {
+	testRepo(t)
+
+	err := Commit(context.Background(), []string{"missing.go"}, "msg")
+	if err == nil || !strings.Contains(err.Error(), "missing.go") {
+		t.Errorf("Commit of a missing file = %v, want git's stderr", err)
+	}
+}
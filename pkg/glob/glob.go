@@ -0,0 +1,55 @@
+package glob
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Match reports whether name matches pattern. Both are slash-separated
+// (name is converted with filepath.ToSlash); segments use path.Match syntax
+// and a "**" segment matches any number of directories. A pattern without a
+// slash is matched against the base name, so "*_test.go" matches at any depth.
+func Match(pattern, name string) (bool, error) {
+	pats := strings.Split(pattern, "/")
+	for _, p := range pats {
+		if _, err := path.Match(p, ""); err != nil {
+			return false, err
+		}
+	}
+
+	name = filepath.ToSlash(filepath.Clean(name))
+	if len(pats) == 1 && pats[0] != "**" {
+		return path.Match(pattern, path.Base(name))
+	}
+	return match(pats, strings.Split(name, "/")), nil
+}
+
+// Valid reports whether pattern is well formed.
+func Valid(pattern string) error {
+	_, err := Match(pattern, "")
+	return err
+}
+
+func match(pats, segs []string) bool {
+	if len(pats) == 0 {
+		return len(segs) == 0
+	}
+
+	if pats[0] == "**" {
+		for i := 0; i <= len(segs); i++ {
+			if match(pats[1:], segs[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(segs) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pats[0], segs[0]); !ok {
+		return false
+	}
+	return match(pats[1:], segs[1:])
+}
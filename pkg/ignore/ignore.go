@@ -0,0 +1,155 @@
+package ignore
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"goscaffold/pkg/glob"
+)
+
+// File is the repo-local ignore file read by import.
+const File = ".goscaffoldignore"
+
+// Matcher holds gitignore-style rules. Blank lines and lines starting with
+// "#" are skipped, "!" negates, a trailing "/" only matches directories and
+// a pattern containing a slash is anchored to the root. The last matching
+// rule wins. As in git, "dir/**" matches what is inside dir but not dir
+// itself, and a file inside an ignored directory cannot be re-included by
+// a negation; re-include the directory first. A nil Matcher ignores
+// nothing.
+type Matcher struct {
+	rules []rule
+}
+
+type rule struct {
+	pattern string // as written, for log messages
+	glob    string
+	negate  bool
+	dirOnly bool
+	// anchored rules with a single segment only match at the top level.
+	anchored bool
+}
+
+// Load reads the rules in path. A missing file yields an empty Matcher.
+func Load(path string) (*Matcher, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Matcher{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	m, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// Parse reads rules from r, one per line.
+func Parse(r io.Reader) (*Matcher, error) {
+	m := &Matcher{}
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimRight(sc.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		r := rule{pattern: line}
+		if strings.HasPrefix(line, "\\#") || strings.HasPrefix(line, "\\!") {
+			line = line[1:]
+		} else if rest, ok := strings.CutPrefix(line, "!"); ok {
+			r.negate = true
+			line = rest
+		}
+		if rest, ok := strings.CutSuffix(line, "/"); ok {
+			r.dirOnly = true
+			line = rest
+		}
+		if strings.Contains(line, "/") {
+			r.anchored = true
+			line = strings.TrimPrefix(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+
+		if err := glob.Valid(line); err != nil {
+			return nil, fmt.Errorf("line %d: invalid pattern %q: %w", n, r.pattern, err)
+		}
+		r.glob = line
+		m.rules = append(m.rules, r)
+	}
+	return m, sc.Err()
+}
+
+// Match reports whether name, a file, is ignored and, if so, the rule
+// responsible. A file is ignored when one of its parent directories is, or
+// else when the last rule matching it does not negate.
+func (m *Matcher) Match(name string) (string, bool) {
+	if m == nil || len(m.rules) == 0 {
+		return "", false
+	}
+
+	name = filepath.ToSlash(filepath.Clean(name))
+	segs := strings.Split(name, "/")
+	for i := 1; i < len(segs); i++ {
+		if by, ok := m.match(strings.Join(segs[:i], "/"), true); ok {
+			return by, true
+		}
+	}
+	return m.match(name, false)
+}
+
+// match applies every rule to name, a directory if dir is set, and reports
+// whether the last one matching ignores it.
+func (m *Matcher) match(name string, dir bool) (string, bool) {
+	var ignored bool
+	var by string
+	for _, r := range m.rules {
+		if r.dirOnly && !dir {
+			continue
+		}
+		if r.match(name) {
+			ignored, by = !r.negate, r.pattern
+		}
+	}
+	if !ignored {
+		return "", false
+	}
+	return by, true
+}
+
+func (r rule) match(name string) bool {
+	if !r.anchored {
+		// glob.Match compares slash-free patterns against the base name.
+		ok, _ := glob.Match(r.glob, name)
+		return ok
+	}
+	// "dir/**" matches everything inside dir, but not dir itself.
+	if dir, ok := strings.CutSuffix(r.glob, "/**"); ok && matchAnchored(dir, name) {
+		return false
+	}
+	return matchAnchored(r.glob, name)
+}
+
+// matchAnchored matches pattern against name from the root, so a pattern
+// of one segment only matches at the top level.
+func matchAnchored(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, name)
+		return ok && !strings.Contains(name, "/")
+	}
+	ok, _ := glob.Match(pattern, name)
+	return ok
+}
@@ -0,0 +1,76 @@
+package ignore
+
+import (
+	"strings"
+	"testing"
+)
+
+func parse(t *testing.T, rules string) *Matcher {
+	t.Helper()
+	m, err := Parse(strings.NewReader(rules))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules string
+		path  string
+		want  bool
+	}{
+		{"basename anywhere", "*.env", "config/prod.env", true},
+		{"no match", "*.env", "main.go", false},
+		{"comment", "# *.go", "main.go", false},
+		{"escaped hash", "\\#notes", "#notes", true},
+		{"anchored top level", "/build", "build", true},
+		{"anchored not nested", "/build", "sub/build", false},
+		{"dir only", "logs/", "logs/a.txt", true},
+		{"dir only not file", "logs/", "logs", false},
+		{"unanchored dir", "node_modules", "web/node_modules/x.js", true},
+		{"double star", "secrets/**", "secrets/a/b.key", true},
+		{"double star not self", "secrets/**", "secrets", false},
+
+		{"negation", "*.env\n!example.env", "example.env", false},
+		{"negation then rule", "!example.env\n*.env", "example.env", true},
+		{"escaped bang", "\\!important", "!important", true},
+		{"negation inside double star", "secrets/**\n!secrets/keep.txt", "secrets/keep.txt", false},
+		{"negation inside double star others", "secrets/**\n!secrets/keep.txt", "secrets/other.txt", true},
+		{"negation under excluded dir", "secrets/\n!secrets/keep.txt", "secrets/keep.txt", true},
+		{"negation under excluded dir pattern", "secrets\n!secrets/keep.txt", "secrets/keep.txt", true},
+		{"negation under nested excluded dir", "secrets/**\n!secrets/sub/keep.txt", "secrets/sub/keep.txt", true},
+		{"directory re-included", "secrets/\n!secrets/", "secrets/keep.txt", false},
+		{"file negation inside re-included dir", "secrets/**\n!secrets/sub/\n!secrets/sub/keep.txt", "secrets/sub/keep.txt", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, got := parse(t, tt.rules).Match(tt.path)
+			if got != tt.want {
+				t.Errorf("rules %q: Match(%s) = %v, want %v", tt.rules, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchReportsRule(t *testing.T) {
+	m := parse(t, "secrets/\n!secrets/keep.txt\n")
+	rule, ok := m.Match("secrets/keep.txt")
+	if !ok || rule != "secrets/" {
+		t.Errorf("Match = %q, %v; want the directory rule", rule, ok)
+	}
+}
+
+func TestNilMatcher(t *testing.T) {
+	var m *Matcher
+	if _, ok := m.Match("anything"); ok {
+		t.Error("nil Matcher ignored a path")
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	if _, err := Parse(strings.NewReader("ok\n[abc\n")); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Parse error = %v, want one naming line 2", err)
+	}
+}
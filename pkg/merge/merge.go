@@ -0,0 +1,64 @@
+package merge
+
+import (
+	"slices"
+	"strings"
+
+	"goscaffold/pkg/diff"
+)
+
+const (
+	markerMine   = "<<<<<<< current"
+	markerSep    = "======="
+	markerTheirs = ">>>>>>> incoming"
+)
+
+// ThreeWay merges the changes from base to mine and from base to theirs line
+// by line. Overlapping changes are written between conflict markers and
+// reported through the second result.
+func ThreeWay(base, mine, theirs string) (string, bool) {
+	b, m, t := diff.SplitLines(base), diff.SplitLines(mine), diff.SplitLines(theirs)
+	toMine := diff.Matches(b, m)
+	toTheirs := diff.Matches(b, t)
+
+	var (
+		out      []string
+		conflict bool
+		i, x, y  int
+	)
+
+	emit := func(bEnd, mEnd, tEnd int) {
+		baseChunk, mineChunk, theirsChunk := b[i:bEnd], m[x:mEnd], t[y:tEnd]
+		switch {
+		case slices.Equal(mineChunk, theirsChunk), slices.Equal(theirsChunk, baseChunk):
+			out = append(out, mineChunk...)
+		case slices.Equal(mineChunk, baseChunk):
+			out = append(out, theirsChunk...)
+		default:
+			conflict = true
+			out = append(out, markerMine)
+			out = append(out, mineChunk...)
+			out = append(out, markerSep)
+			out = append(out, theirsChunk...)
+			out = append(out, markerTheirs)
+		}
+	}
+
+	// Base lines kept by both sides are stable anchors; everything between
+	// two anchors is resolved as one chunk.
+	for k := range b {
+		if toMine[k] < 0 || toTheirs[k] < 0 {
+			continue
+		}
+		emit(k, toMine[k], toTheirs[k])
+		out = append(out, b[k])
+		i, x, y = k+1, toMine[k]+1, toTheirs[k]+1
+	}
+	emit(len(b), len(m), len(t))
+
+	result := strings.Join(out, "\n")
+	if len(out) > 0 && strings.HasSuffix(theirs, "\n") {
+		result += "\n"
+	}
+	return result, conflict
+}
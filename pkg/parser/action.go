@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"strings"
+
+	"goscaffold/internal/models"
+)
+
+const (
+	actionToken = "action:"
+	toToken     = "to:"
+)
+
+// commentMarkers may wrap a directive, so it can sit in a comment in the
+// block's own language. The value is the closing marker, if any.
+var commentMarkers = []struct{ open, close string }{
+	{"<!--", "-->"},
+	{"/*", "*/"},
+	{"//", ""},
+	{"#", ""},
+	{"--", ""},
+}
+
+// uncomment trims line and strips one comment marker around it.
+func uncomment(line string) string {
+	line = strings.TrimSpace(line)
+	for _, m := range commentMarkers {
+		if rest, ok := strings.CutPrefix(line, m.open); ok {
+			if m.close != "" {
+				rest = strings.TrimSuffix(strings.TrimSpace(rest), m.close)
+			}
+			return strings.TrimSpace(rest)
+		}
+	}
+	return line
+}
+
+// parseDirective reads a delete or rename directive outside code blocks:
+//
+//	// action: delete path: old.go
+//	// action: rename path: a.go to: b.go
+//
+// Values may follow their key directly (path:old.go) or after a space.
+func parseDirective(line string) (models.File, bool) {
+	line = uncomment(line)
+	if !strings.HasPrefix(line, actionToken) {
+		return models.File{}, false
+	}
+
+	values := make(map[string]string)
+	fields := strings.Fields(line)
+	for i := 0; i < len(fields); i++ {
+		key, val, ok := strings.Cut(fields[i], ":")
+		if !ok {
+			continue
+		}
+		if val == "" && i+1 < len(fields) && !strings.HasSuffix(fields[i+1], ":") {
+			i++
+			val = fields[i]
+		}
+		values[key+":"] = val
+	}
+
+	action, err := models.ParseAction(values[actionToken])
+	if err != nil || (action != models.ActionDelete && action != models.ActionRename) {
+		return models.File{}, false
+	}
+	if values[pathToken] == "" {
+		return models.File{}, false
+	}
+	return models.File{Path: values[pathToken], Action: action, NewPath: values[toToken]}, true
+}
@@ -0,0 +1,77 @@
+package parser
+
+import (
+	"testing"
+
+	"goscaffold/internal/models"
+)
+
+func TestParseDirective(t *testing.T) {
+	tests := []struct {
+		line string
+		want models.File
+		ok   bool
+	}{
+		{"// action: delete path: old.go", models.File{Path: "old.go", Action: models.ActionDelete}, true},
+		{"action:delete path:old.go", models.File{Path: "old.go", Action: models.ActionDelete}, true},
+		{"# action: rename path: a.py to: pkg/b.py", models.File{Path: "a.py", Action: models.ActionRename, NewPath: "pkg/b.py"}, true},
+		{"<!-- action: Rename path: a.md to: b.md -->", models.File{Path: "a.md", Action: models.ActionRename, NewPath: "b.md"}, true},
+		{"/* action: delete path: x.c */", models.File{Path: "x.c", Action: models.ActionDelete}, true},
+		{"// action: delete", models.File{}, false},
+		{"// action: create path: new.go", models.File{}, false},
+		{"// action: explode path: x.go", models.File{}, false},
+		{"// path: main.go", models.File{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseDirective(tt.line)
+		if ok != tt.ok || got.Path != tt.want.Path || got.Action != tt.want.Action || got.NewPath != tt.want.NewPath {
+			t.Errorf("parseDirective(%q) = %+v, %v; want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseActions(t *testing.T) {
+	markdown := "// action: delete path: old.go\n" +
+		"```go\n// path: new.go\npackage x\n```\n" +
+		"# action: rename path: a.go to: b.go\n"
+	yaml := "---\npath: new.go\naction: create\n---\npackage x\n" +
+		"---\npath: main.go\naction: update\n---\npackage main\n" +
+		"---\npath: old.go\naction: delete\n---\n" +
+		"---\npath: a.go\naction: rename\nto: b.go\n---\n"
+
+	tests := []struct {
+		name   string
+		input  string
+		format Format
+		want   []models.File
+	}{
+		{"markdown", markdown, FormatMarkdown, []models.File{
+			{Path: "old.go", Action: models.ActionDelete},
+			{Path: "new.go"},
+			{Path: "a.go", Action: models.ActionRename, NewPath: "b.go"},
+		}},
+		{"yaml", yaml, FormatYAML, []models.File{
+			{Path: "new.go", Action: models.ActionCreate},
+			{Path: "main.go", Action: models.ActionUpdate},
+			{Path: "old.go", Action: models.ActionDelete},
+			{Path: "a.go", Action: models.ActionRename, NewPath: "b.go"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, invalid := ParseWithOptions(tt.input, Options{Format: tt.format})
+			if len(invalid) != 0 {
+				t.Fatalf("invalid: %+v", invalid)
+			}
+			if len(files) != len(tt.want) {
+				t.Fatalf("got %d files, want %d: %+v", len(files), len(tt.want), files)
+			}
+			for i, want := range tt.want {
+				got := files[i]
+				if got.Path != want.Path || got.Action != want.Action || got.NewPath != want.NewPath {
+					t.Errorf("file %d = {%s %s %s}, want {%s %s %s}", i, got.Path, got.Action, got.NewPath, want.Path, want.Action, want.NewPath)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"goscaffold/internal/models"
+)
+
+const (
+	encodingToken  = "encoding:"
+	encodingBase64 = "base64"
+)
+
+// identityEncodings name the default, plain text, so declaring them changes
+// nothing.
+var identityEncodings = map[string]bool{"utf-8": true, "utf8": true, "text": true}
+
+// parseEncoding reads an "encoding: base64" line, optionally inside a
+// comment, at the top of a block. Other values are left alone: lines like
+// "# encoding: utf-8" are Python and Ruby magic comments, not directives.
+func parseEncoding(line string) (string, bool) {
+	enc, ok := strings.CutPrefix(uncomment(line), encodingToken)
+	if !ok {
+		return "", false
+	}
+	enc = strings.ToLower(strings.TrimSpace(enc))
+	if enc != encodingBase64 {
+		return "", false
+	}
+	return enc, true
+}
+
+// decode replaces the code of base64 files with the bytes it encodes, so
+// content survives copy and paste exactly. An identity encoding such as
+// utf-8 is dropped. Files that fail to decode, or declare an unknown
+// encoding, are rejected with the reason as a warning.
+// When enabled is false encoded files are kept as they are, with a warning.
+func decode(files []models.File, enabled bool) (decoded, rejected []models.File) {
+	for _, f := range files {
+		if identityEncodings[f.Encoding] {
+			f.Encoding = ""
+		}
+		switch {
+		case f.Encoding == "":
+		case f.Encoding != encodingBase64:
+			f.Warnings = append(f.Warnings, fmt.Sprintf("unknown encoding %q", f.Encoding))
+			rejected = append(rejected, f)
+			continue
+		case !enabled:
+			f.Warnings = append(f.Warnings, "block is base64-encoded but decoding is off")
+		default:
+			data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(f.Code), ""))
+			if err != nil {
+				f.Warnings = append(f.Warnings, fmt.Sprintf("invalid base64: %v", err))
+				rejected = append(rejected, f)
+				continue
+			}
+			f.Code = string(data)
+		}
+		decoded = append(decoded, f)
+	}
+	return decoded, rejected
+}
@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func TestBase64RoundTrip(t *testing.T) {
+	want := "\x00\x01binary\xff\nline two\r\n"
+	enc := base64.StdEncoding.EncodeToString([]byte(want))
+	input := "```\n// path: assets/blob.bin\n// encoding: base64\n" + enc[:10] + "\n" + enc[10:] + "\n```\n"
+
+	files, invalid := ParseWithOptions(input, Options{Base64: true})
+	if len(invalid) != 0 {
+		t.Fatalf("invalid files: %+v", invalid)
+	}
+	if len(files) != 1 {
+		t.Fatalf("got %d files, want 1", len(files))
+	}
+	if files[0].Code != want {
+		t.Errorf("decoded code = %q, want %q", files[0].Code, want)
+	}
+}
+
+func TestBase64Disabled(t *testing.T) {
+	input := "```\n# path: a.bin\n# encoding: base64\naGVsbG8=\n```\n"
+
+	files, _ := ParseWithOptions(input, Options{})
+	if len(files) != 1 {
+		t.Fatalf("got %d files, want 1", len(files))
+	}
+	if files[0].Code != "aGVsbG8=\n" {
+		t.Errorf("code = %q, want it left encoded", files[0].Code)
+	}
+	if len(files[0].Warnings) == 0 {
+		t.Error("expected a warning about the undecoded block")
+	}
+}
+
+func TestEncodingMagicCommentKept(t *testing.T) {
+	input := "```python\n# path: app/main.py\n# encoding: utf-8\nprint('hi')\n```\n" +
+		"```yaml\n# path: conf.yaml\nencoding: latin1\nname: x\n```\n"
+
+	for _, b64 := range []bool{false, true} {
+		files, invalid := ParseWithOptions(input, Options{Base64: b64})
+		if len(invalid) != 0 {
+			t.Fatalf("base64=%v: invalid files: %+v", b64, invalid)
+		}
+		if len(files) != 2 {
+			t.Fatalf("base64=%v: got %d files, want 2", b64, len(files))
+		}
+		if got := files[0].Code; !strings.HasPrefix(got, "# encoding: utf-8\n") {
+			t.Errorf("base64=%v: magic comment dropped: %q", b64, got)
+		}
+		if got := files[1].Code; got != "encoding: latin1\nname: x\n" {
+			t.Errorf("base64=%v: yaml code = %q", b64, got)
+		}
+		for _, f := range files {
+			if f.Encoding != "" {
+				t.Errorf("base64=%v: %s: encoding = %q, want none", b64, f.Path, f.Encoding)
+			}
+		}
+	}
+}
+
+func TestIdentityEncodingHeader(t *testing.T) {
+	for _, enc := range []string{"utf-8", "UTF8", "text"} {
+		input := "---\npath: notes.txt\nencoding: " + enc + "\n---\nhello\n"
+
+		files, invalid := ParseWithOptions(input, Options{Base64: true})
+		if len(invalid) != 0 {
+			t.Fatalf("%s: invalid files: %+v", enc, invalid)
+		}
+		if len(files) != 1 {
+			t.Fatalf("%s: got %d files, want 1", enc, len(files))
+		}
+		if files[0].Code != "hello\n" || files[0].Encoding != "" {
+			t.Errorf("%s: code = %q, encoding = %q", enc, files[0].Code, files[0].Encoding)
+		}
+	}
+}
+
+func TestUnknownEncodingHeaderRejected(t *testing.T) {
+	input := "---\npath: notes.txt\nencoding: rot13\n---\nhello\n"
+
+	files, invalid := ParseWithOptions(input, Options{Base64: true})
+	if len(files) != 0 || len(invalid) != 1 {
+		t.Fatalf("files %+v, invalid %+v; want notes.txt rejected", files, invalid)
+	}
+}
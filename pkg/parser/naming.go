@@ -0,0 +1,86 @@
+package parser
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Namer builds a file name for the n-th (1-based) block that has no path.
+type Namer func(n int, lang, code string) string
+
+const (
+	StrategySequential = "sequential"
+	StrategyHash       = "hash"
+	StrategyIdentifier = "identifier"
+)
+
+var langExt = map[string]string{
+	"go":         "go",
+	"golang":     "go",
+	"python":     "py",
+	"py":         "py",
+	"javascript": "js",
+	"js":         "js",
+	"typescript": "ts",
+	"ts":         "ts",
+	"json":       "json",
+	"yaml":       "yaml",
+	"yml":        "yaml",
+	"toml":       "toml",
+	"sh":         "sh",
+	"bash":       "sh",
+	"shell":      "sh",
+	"rust":       "rs",
+	"java":       "java",
+	"c":          "c",
+	"cpp":        "cpp",
+	"c++":        "cpp",
+	"html":       "html",
+	"css":        "css",
+	"sql":        "sql",
+	"markdown":   "md",
+	"md":         "md",
+	"dockerfile": "dockerfile",
+}
+
+var identRe = regexp.MustCompile(`(?m)^\s*(?:package|func|def|class|function|fn|type|interface|struct)\s+([A-Za-z_]\w*)`)
+
+func NamerFor(strategy string) (Namer, error) {
+	switch strategy {
+	case "", StrategySequential:
+		return nameSequential, nil
+	case StrategyHash:
+		return nameHash, nil
+	case StrategyIdentifier:
+		return nameIdentifier, nil
+	default:
+		return nil, fmt.Errorf("unknown unnamed strategy %q (want %s, %s or %s)",
+			strategy, StrategySequential, StrategyHash, StrategyIdentifier)
+	}
+}
+
+func extFor(lang string) string {
+	if ext, ok := langExt[strings.ToLower(lang)]; ok {
+		return ext
+	}
+	return "txt"
+}
+
+func nameSequential(n int, lang, code string) string {
+	return fmt.Sprintf("block-%d.%s", n, extFor(lang))
+}
+
+func nameHash(n int, lang, code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:6]) + "." + extFor(lang)
+}
+
+func nameIdentifier(n int, lang, code string) string {
+	if m := identRe.FindStringSubmatch(code); m != nil {
+		return m[1] + "." + extFor(lang)
+	}
+	return nameSequential(n, lang, code)
+}
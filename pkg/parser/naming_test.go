@@ -0,0 +1,78 @@
+package parser
+
+import (
+	"regexp"
+	"slices"
+	"testing"
+)
+
+func TestNamerFor(t *testing.T) {
+	const goCode = "// helpers\npackage util\n\nfunc Add() {}\n"
+	const pyCode = "import os\n\ndef load_config():\n    pass\n"
+
+	tests := []struct {
+		strategy string
+		n        int
+		lang     string
+		code     string
+		want     string
+	}{
+		{"", 1, "go", goCode, "block-1.go"},
+		{StrategySequential, 3, "Python", pyCode, "block-3.py"},
+		{StrategySequential, 2, "unknown", "x", "block-2.txt"},
+		{StrategyIdentifier, 1, "go", goCode, "util.go"},
+		{StrategyIdentifier, 1, "py", pyCode, "load_config.py"},
+		{StrategyIdentifier, 4, "sh", "echo hi\n", "block-4.sh"},
+	}
+	for _, tt := range tests {
+		namer, err := NamerFor(tt.strategy)
+		if err != nil {
+			t.Fatalf("NamerFor(%q): %v", tt.strategy, err)
+		}
+		if got := namer(tt.n, tt.lang, tt.code); got != tt.want {
+			t.Errorf("%s: name(%d, %q) = %q, want %q", tt.strategy, tt.n, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestNamerForHash(t *testing.T) {
+	namer, err := NamerFor(StrategyHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	a := namer(1, "go", "package a\n")
+	if !regexp.MustCompile(`^[0-9a-f]{12}\.go$`).MatchString(a) {
+		t.Errorf("hash name %q, want 12 hex digits and .go", a)
+	}
+	if b := namer(2, "golang", "package a\n"); b != a {
+		t.Errorf("same code named %q and %q, want the same name", a, b)
+	}
+	if c := namer(1, "go", "package c\n"); c == a {
+		t.Errorf("different code both named %q", a)
+	}
+}
+
+func TestNamerForUnknown(t *testing.T) {
+	if _, err := NamerFor("random"); err == nil {
+		t.Error("NamerFor(random) succeeded")
+	}
+}
+
+func TestParseUnnamedBlocks(t *testing.T) {
+	namer, err := NamerFor(StrategyIdentifier)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := "```go\npackage util\n```\n```go\npackage util\n\nfunc X() {}\n```\n```go\n// path: keep.go\npackage keep\n```\n"
+
+	files, _ := ParseWithOptions(input, Options{Unnamed: namer})
+	var got []string
+	for _, f := range files {
+		got = append(got, f.Path)
+	}
+	want := []string{"util.go", "util-2.go", "keep.go"}
+	if !slices.Equal(got, want) {
+		t.Errorf("paths %v, want %v", got, want)
+	}
+}
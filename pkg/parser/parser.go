@@ -1,30 +1,353 @@
 package parser
 
 import (
-	"regexp"
+	"fmt"
+	"path/filepath"
+	"strconv"
 	"strings"
+
+	"goscaffold/internal/models"
+	"goscaffold/pkg/diff"
+)
+
+const (
+	fence     = "```"
+	pathToken = "path:"
+	bom       = "\ufeff"
+)
+
+// Format selects how input is parsed.
+type Format string
+
+const (
+	// FormatAuto picks markdown or yaml from whichever marker comes first.
+	FormatAuto     Format = "auto"
+	FormatMarkdown Format = "markdown"
+	FormatYAML     Format = "yaml"
+	// FormatDiff reads the input as a unified diff against existing files.
+	FormatDiff Format = "diff"
 )
 
-type File struct {
-	Path string
-	Code string
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case "", FormatAuto:
+		return FormatAuto, nil
+	case FormatMarkdown, FormatYAML, FormatDiff:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unknown format %q (want %s, %s, %s or %s)", s, FormatMarkdown, FormatYAML, FormatDiff, FormatAuto)
+	}
+}
+
+type Options struct {
+	// Format forces a parser; the zero value auto-detects.
+	Format Format
+
+	// Unnamed, when set, names blocks without a path instead of dropping them.
+	Unnamed Namer
+
+	// StripPrefix removes this leading directory, e.g. "myproject/", and
+	// StripComponents then removes that many leading path components, before
+	// paths are validated.
+	StripPrefix     string
+	StripComponents int
+
+	// DropInvalid discards files whose path fails models.File.Validate.
+	// Otherwise the error is kept as a warning on the file.
+	DropInvalid bool
+
+	// Base64 decodes blocks that declare "encoding: base64". Without it such
+	// blocks are kept encoded, with a warning.
+	Base64 bool
+
+	// Trace, when set, is told each parsing decision: fences opened and
+	// closed, paths found or missing, and files accepted or rejected. It
+	// is for debugging inputs that do not parse as expected.
+	Trace TraceFunc
+
+	// ReadFile reads the files that diffs are applied to; nil means
+	// os.ReadFile. Files from diffs that do not apply are always returned
+	// as invalid, with the reason as a warning.
+	ReadFile func(path string) ([]byte, error)
 }
 
-func Parse(content string) []File {
-	var files []File
+// TraceFunc receives one parser decision. Line is the 1-based input line,
+// or 0 for decisions about the whole input.
+type TraceFunc func(line int, event, detail string)
 
-	// Pattern for: ```go path:filename.go
-	re := regexp.MustCompile("(?s)```(?:[\\w\\+]+)?\\s+path:(\\S+)\\n(.*?)```")
-	matches := re.FindAllStringSubmatch(content, -1)
+func Parse(content string, format Format) []models.File {
+	files, _ := ParseWithOptions(content, Options{Format: format})
+	return files
+}
+
+func ParseWithOptions(content string, opts Options) ([]models.File, []models.File) {
+	content, crlf := normalize(content)
 
-	for _, match := range matches {
-		if len(match) >= 3 {
-			files = append(files, File{
-				Path: strings.TrimSpace(match[1]),
-				Code: strings.TrimSpace(match[2]),
-			})
+	files, patches := parse(content, opts)
+	for i := range files {
+		files[i].CRLF = crlf
+		files[i].Path = stripPath(files[i].Path, opts.StripPrefix, opts.StripComponents)
+		if files[i].NewPath != "" {
+			files[i].NewPath = stripPath(files[i].NewPath, opts.StripPrefix, opts.StripComponents)
 		}
 	}
 
-	return files
+	files, rejected := decode(files, opts.Base64)
+	patched, unapplied := applyPatches(patches, opts)
+	valid, invalid := validate(append(files, patched...), opts.DropInvalid)
+	invalid = append(append(invalid, rejected...), unapplied...)
+
+	if opts.Trace != nil {
+		for _, f := range valid {
+			opts.Trace(f.StartLine, "accept", f.Path)
+		}
+		for _, f := range invalid {
+			opts.Trace(f.StartLine, "reject", f.Path+": "+strings.Join(f.Warnings, "; "))
+		}
+	}
+	return valid, invalid
+}
+
+// stripPath removes prefix (matched on whole segments) and then n leading
+// components from a slash-separated path.
+func stripPath(path, prefix string, n int) string {
+	if prefix = strings.Trim(prefix, "/"); prefix != "" {
+		if rest, ok := strings.CutPrefix(path, prefix+"/"); ok {
+			path = rest
+		}
+	}
+	for ; n > 0 && path != ""; n-- {
+		_, rest, _ := strings.Cut(path, "/")
+		path = rest
+	}
+	return path
+}
+
+// normalize strips a leading UTF-8 BOM and converts CRLF line endings to LF,
+// reporting whether any CRLF was found.
+func normalize(content string) (string, bool) {
+	content = strings.TrimPrefix(content, bom)
+	if !strings.Contains(content, "\r\n") {
+		return content, false
+	}
+	return strings.ReplaceAll(content, "\r\n", "\n"), true
+}
+
+func parse(content string, opts Options) ([]models.File, []patchBlock) {
+	format := opts.Format
+	if format == "" || format == FormatAuto {
+		format = detect(content)
+	}
+	if opts.Trace != nil {
+		opts.Trace(0, "format", string(format))
+	}
+
+	switch format {
+	case FormatYAML:
+		return parseYAMLStyle(content), nil
+	case FormatDiff:
+		return nil, []patchBlock{{text: content, start: 1, end: strings.Count(content, "\n") + 1}}
+	}
+	return parseMarkdown(content, opts)
+}
+
+// detect returns FormatYAML or FormatDiff when a front-matter header or diff
+// header appears before any code fence, and FormatMarkdown otherwise.
+func detect(content string) Format {
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(strings.TrimSpace(line), fence) {
+			return FormatMarkdown
+		}
+		if isHeaderStart(lines, i) {
+			return FormatYAML
+		}
+		if isDiffStart(lines, i) {
+			return FormatDiff
+		}
+	}
+	return FormatMarkdown
+}
+
+// validate attaches path validation errors as warnings and, if drop is set,
+// splits the invalid files out into the second result.
+func validate(files []models.File, drop bool) (valid, invalid []models.File) {
+	for _, f := range files {
+		if err := f.Validate(); err != nil {
+			f.Warnings = append(f.Warnings, err.Error())
+			if drop {
+				invalid = append(invalid, f)
+				continue
+			}
+		}
+		valid = append(valid, f)
+	}
+	return valid, invalid
+}
+
+// parseMarkdown extracts fenced blocks carrying a path, either on the fence
+// line (```go path:main.go) or on the first line inside the block, bare or
+// in a comment (# path: app.py, <!-- path: index.html -->). Blocks
+// fenced as diff or patch that hold a unified diff are returned as patches,
+// and delete and rename directives between blocks become files carrying
+// that action.
+func parseMarkdown(content string, opts Options) ([]models.File, []patchBlock) {
+	var (
+		files   []models.File
+		patches []patchBlock
+		inBlock bool
+		hasCode bool
+		path    string
+		lang    string
+		enc     string
+		start   int
+		unnamed int
+		code    strings.Builder
+		used    = make(map[string]bool)
+	)
+
+	// Details are only built when tracing, so parsing without a tracer
+	// costs nothing extra.
+	tracing := opts.Trace != nil
+	trace := func(line int, event, detail string) {
+		if tracing {
+			opts.Trace(line, event, detail)
+		}
+	}
+
+	for i, line := range strings.Split(content, "\n") {
+		lineNo := i + 1
+		trimmed := strings.TrimSpace(line)
+
+		if !inBlock {
+			if f, ok := parseDirective(line); ok {
+				if tracing {
+					trace(lineNo, "directive", string(f.Action)+" "+f.Path)
+				}
+				f.StartLine, f.EndLine = lineNo, lineNo
+				files = append(files, f)
+				continue
+			}
+			if strings.HasPrefix(trimmed, fence) {
+				inBlock = true
+				hasCode = false
+				enc = ""
+				start = lineNo
+				lang, path = parseInfo(strings.TrimPrefix(trimmed, fence))
+				code.Reset()
+				if tracing {
+					trace(lineNo, "fence-open", "lang="+lang+" path="+path)
+				}
+			}
+			continue
+		}
+
+		if strings.HasPrefix(trimmed, fence) {
+			body := blockBody(code.String())
+			if path == "" && patchLangs[strings.ToLower(lang)] && diff.IsPatch(body) {
+				trace(lineNo, "fence-close", "patch")
+				patches = append(patches, patchBlock{text: body, start: start, end: lineNo})
+				inBlock = false
+				continue
+			}
+			if path == "" && opts.Unnamed != nil && body != "" {
+				unnamed++
+				path = uniqueName(opts.Unnamed(unnamed, lang, body), used)
+				trace(lineNo, "path-generated", path)
+			}
+			if tracing {
+				switch {
+				case path != "":
+					trace(lineNo, "fence-close", path)
+				case body == "":
+					trace(lineNo, "fence-close", "empty block without a path, skipped")
+				default:
+					trace(lineNo, "path-missing", "block opened on line "+strconv.Itoa(start)+" has no path, skipped")
+				}
+			}
+			if path != "" {
+				used[path] = true
+				files = append(files, models.File{
+					Path:      path,
+					Code:      body,
+					Language:  lang,
+					Encoding:  enc,
+					StartLine: start,
+					EndLine:   lineNo,
+				})
+			}
+			inBlock = false
+			continue
+		}
+
+		if path == "" && !hasCode {
+			if p, ok := strings.CutPrefix(uncomment(trimmed), pathToken); ok {
+				path = strings.TrimSpace(p)
+				trace(lineNo, "path", path)
+				continue
+			}
+		}
+		if !hasCode && enc == "" {
+			if e, ok := parseEncoding(trimmed); ok {
+				enc = e
+				trace(lineNo, "encoding", e)
+				continue
+			}
+		}
+
+		if trimmed != "" {
+			hasCode = true
+		}
+		code.WriteString(line)
+		code.WriteByte('\n')
+	}
+
+	if inBlock {
+		trace(start, "fence-unclosed", "block never closed, skipped")
+	}
+	return files, patches
+}
+
+// blockBody drops the blank lines surrounding a block's code and ends it
+// with a single newline. Indentation of the first line is preserved.
+func blockBody(raw string) string {
+	lines := strings.Split(raw, "\n")
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// parseInfo splits a fence info string into its language and path hint.
+func parseInfo(info string) (lang, path string) {
+	for _, field := range strings.Fields(info) {
+		if p, ok := strings.CutPrefix(field, pathToken); ok {
+			if p != "" {
+				path = p
+			}
+		} else if lang == "" {
+			lang = field
+		}
+	}
+	return lang, path
+}
+
+func uniqueName(name string, used map[string]bool) string {
+	if !used[name] {
+		return name
+	}
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s-%d%s", base, i, ext)
+		if !used[candidate] {
+			return candidate
+		}
+	}
 }
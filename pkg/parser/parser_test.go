@@ -0,0 +1,210 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"goscaffold/internal/models"
+)
+
+type lineRange struct {
+	path       string
+	start, end int
+}
+
+func ranges(files []models.File) []lineRange {
+	var out []lineRange
+	for _, f := range files {
+		out = append(out, lineRange{f.Path, f.StartLine, f.EndLine})
+	}
+	return out
+}
+
+func TestLineRanges(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		input  string
+		want   []lineRange
+	}{
+		{
+			name:   "markdown",
+			format: FormatMarkdown,
+			input: "Here are the files.\n" + // 1
+				"\n" + // 2
+				"```go path:cmd/main.go\n" + // 3
+				"package main\n" + // 4
+				"```\n" + // 5
+				"Some prose.\n" + // 6
+				"```python\n" + // 7
+				"# path: app/util.py\n" + // 8
+				"def f():\n" + // 9
+				"    pass\n" + // 10
+				"```\n" + // 11
+				"```\n" + // 12
+				"no path here\n" + // 13
+				"```\n" + // 14
+				"```yaml path:conf.yaml\n" + // 15
+				"a: 1\n" + // 16
+				"```\n", // 17
+			want: []lineRange{
+				{"cmd/main.go", 3, 5},
+				{"app/util.py", 7, 11},
+				{"conf.yaml", 15, 17},
+			},
+		},
+		{
+			name:   "yaml",
+			format: FormatYAML,
+			input: "---\n" + // 1
+				"path: a.go\n" + // 2
+				"---\n" + // 3
+				"package a\n" + // 4
+				"---\n" + // 5
+				"path: b.go\n" + // 6
+				"lang: go\n" + // 7
+				"---\n" + // 8
+				"package b\n" + // 9
+				"\n" + // 10
+				"func B() {}\n", // 11
+			want: []lineRange{
+				{"a.go", 1, 4},
+				{"b.go", 5, 11},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ranges(Parse(tt.input, tt.format))
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("file %d: got %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCRLFAndBOM(t *testing.T) {
+	lf := "```go\n// path: main.go\npackage main\n\nfunc main() {}\n```\n"
+	want := "package main\n\nfunc main() {}\n"
+
+	tests := []struct {
+		name  string
+		input string
+		crlf  bool
+	}{
+		{"lf", lf, false},
+		{"crlf", strings.ReplaceAll(lf, "\n", "\r\n"), true},
+		{"bom", "\ufeff" + lf, false},
+		{"bom crlf", "\ufeff" + strings.ReplaceAll(lf, "\n", "\r\n"), true},
+		{"yaml crlf", "\ufeff---\r\npath: main.go\r\n---\r\npackage main\r\n\r\nfunc main() {}\r\n", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := Parse(tt.input, FormatAuto)
+			if len(files) != 1 {
+				t.Fatalf("got %d files, want 1", len(files))
+			}
+			f := files[0]
+			if f.Path != "main.go" {
+				t.Errorf("path = %q, want main.go", f.Path)
+			}
+			if f.Code != want {
+				t.Errorf("code = %q, want %q", f.Code, want)
+			}
+			if f.CRLF != tt.crlf {
+				t.Errorf("CRLF = %v, want %v", f.CRLF, tt.crlf)
+			}
+		})
+	}
+}
+
+func TestBlockBodyKeepsIndentation(t *testing.T) {
+	input := "```python\n" +
+		"# path: pkg/method.py\n" +
+		"\n" +
+		"    def method(self):\n" +
+		"        return 1\n" +
+		"\n" +
+		"\n" +
+		"```\n"
+
+	files := Parse(input, FormatMarkdown)
+	if len(files) != 1 {
+		t.Fatalf("got %d files, want 1", len(files))
+	}
+	want := "    def method(self):\n        return 1\n"
+	if files[0].Code != want {
+		t.Errorf("code = %q, want %q", files[0].Code, want)
+	}
+}
+
+func TestBlockBodyEndsWithNewline(t *testing.T) {
+	tests := []struct {
+		raw, want string
+	}{
+		{"a\nb\n", "a\nb\n"},
+		{"a\nb\n\n  \n\n", "a\nb\n"},
+		{"\n\na\nb\n", "a\nb\n"},
+		{"a\n\nb\n", "a\n\nb\n"},
+		{"a", "a\n"},
+		{"\n  \n", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := blockBody(tt.raw); got != tt.want {
+			t.Errorf("blockBody(%q) = %q, want %q", tt.raw, got, tt.want)
+		}
+	}
+}
+
+func TestPathHintCommentSyntax(t *testing.T) {
+	tests := []struct {
+		lang, hint, path string
+	}{
+		{"python", "# path: a.py", "a.py"},
+		{"go", "// path: a.go", "a.go"},
+		{"sql", "-- path: a.sql", "a.sql"},
+		{"css", "/* path: a.css */", "a.css"},
+		{"html", "<!-- path: a.html -->", "a.html"},
+		{"", "path: a.txt", "a.txt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.hint, func(t *testing.T) {
+			input := "```" + tt.lang + "\n" + tt.hint + "\nbody\n```\n"
+			files := Parse(input, FormatMarkdown)
+			if len(files) != 1 {
+				t.Fatalf("got %d files, want 1", len(files))
+			}
+			if files[0].Path != tt.path {
+				t.Errorf("path = %q, want %q", files[0].Path, tt.path)
+			}
+			if files[0].Code != "body\n" {
+				t.Errorf("code = %q, want the hint line removed", files[0].Code)
+			}
+		})
+	}
+}
+
+func TestPathHintAfterCodeIsCode(t *testing.T) {
+	input := "```go\n// path: a.go\npackage a\n// path: b.go\n```\n"
+	files := Parse(input, FormatMarkdown)
+	if len(files) != 1 {
+		t.Fatalf("got %d files, want 1", len(files))
+	}
+	if files[0].Path != "a.go" {
+		t.Errorf("path = %q, want a.go", files[0].Path)
+	}
+	if want := "package a\n// path: b.go\n"; files[0].Code != want {
+		t.Errorf("code = %q, want %q", files[0].Code, want)
+	}
+
+	// Without a hint before the code there is no path at all.
+	if files := Parse("```go\npackage a\n// path: b.go\n```\n", FormatMarkdown); len(files) != 0 {
+		t.Errorf("got %+v, want no files", files)
+	}
+}
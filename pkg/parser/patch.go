@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"goscaffold/internal/models"
+	"goscaffold/pkg/diff"
+)
+
+// patchLangs are the fence languages whose blocks are read as unified diffs.
+var patchLangs = map[string]bool{"diff": true, "patch": true}
+
+// patchBlock is a unified diff found in the input. It is applied only once
+// path options have been applied, since applying reads the target file.
+type patchBlock struct {
+	text       string
+	start, end int
+}
+
+// applyPatches applies each patch to the file it names, read through
+// opts.ReadFile. Patches that cannot be parsed, read or applied are returned
+// in rejected with the reason as a warning.
+func applyPatches(blocks []patchBlock, opts Options) (files, rejected []models.File) {
+	read := opts.ReadFile
+	if read == nil {
+		read = os.ReadFile
+	}
+
+	for _, b := range blocks {
+		reject := func(path, format string, args ...any) {
+			rejected = append(rejected, models.File{
+				Path:      path,
+				StartLine: b.start,
+				EndLine:   b.end,
+				Warnings:  []string{fmt.Sprintf(format, args...)},
+			})
+		}
+
+		patches, err := diff.ParsePatch(b.text)
+		if err != nil {
+			reject("", "invalid patch: %v", err)
+			continue
+		}
+
+		for _, p := range patches {
+			if p.Deleted() {
+				files = append(files, models.File{
+					Path:      stripPath(p.OldPath, opts.StripPrefix, opts.StripComponents),
+					Action:    models.ActionDelete,
+					StartLine: b.start,
+					EndLine:   b.end,
+				})
+				continue
+			}
+			path := stripPath(p.NewPath, opts.StripPrefix, opts.StripComponents)
+			action := models.ActionUpdate
+			if p.Created() {
+				action = models.ActionCreate
+			}
+
+			var old string
+			var crlf bool
+			if !p.Created() {
+				data, err := read(path)
+				if err != nil {
+					reject(path, "patch target: %v", err)
+					continue
+				}
+				old, crlf = normalize(string(data))
+			}
+
+			code, err := p.Apply(old)
+			if err != nil {
+				reject(path, "patch for %s: %v", path, err)
+				continue
+			}
+			files = append(files, models.File{
+				Path:      path,
+				Code:      code,
+				Action:    action,
+				StartLine: b.start,
+				EndLine:   b.end,
+				CRLF:      crlf,
+			})
+		}
+	}
+	return files, rejected
+}
+
+// isDiffStart reports whether lines[i] opens a unified diff.
+func isDiffStart(lines []string, i int) bool {
+	return strings.HasPrefix(lines[i], "diff --git ") ||
+		(strings.HasPrefix(lines[i], "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "))
+}
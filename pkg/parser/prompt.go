@@ -0,0 +1,40 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Prompt returns instructions asking an AI assistant to answer in a form
+// this package parses. It is built from the same tokens the parsers match,
+// so the two cannot drift apart.
+func Prompt(format Format) (string, error) {
+	var b strings.Builder
+	b.WriteString("When you write or change files, output every file in full, using exactly this format:\n\n")
+
+	switch format {
+	case "", FormatAuto, FormatMarkdown:
+		fmt.Fprintf(&b, "%sgo %sinternal/app/app.go\n", fence, pathToken)
+		b.WriteString("package app\n")
+		b.WriteString(fence + "\n\n")
+		b.WriteString("Rules:\n")
+		fmt.Fprintf(&b, "- Put each file in its own %s code block.\n", fence)
+		fmt.Fprintf(&b, "- Write the language, a space and %s<path> on the opening fence line.\n", pathToken)
+		fmt.Fprintf(&b, "- If the opening line holds only the language, make the first line of the block %s <path> instead.\n", pathToken)
+	case FormatYAML:
+		b.WriteString(docSep + "\n")
+		fmt.Fprintf(&b, "%s internal/app/app.go\n", pathToken)
+		b.WriteString(docSep + "\n")
+		b.WriteString("package app\n\n")
+		b.WriteString("Rules:\n")
+		fmt.Fprintf(&b, "- Start each file with a %s line, a %s <path> line and another %s line.\n", docSep, pathToken, docSep)
+		b.WriteString("- Follow the header with the file contents; a file ends where the next header starts.\n")
+		fmt.Fprintf(&b, "- Do not wrap the contents in %s fences.\n", fence)
+	default:
+		return "", fmt.Errorf("no prompt for format %q", format)
+	}
+
+	b.WriteString("- Use paths relative to the project root, with forward slashes and no \"..\".\n")
+	b.WriteString("- Do not use placeholders such as \"rest unchanged\"; the file is written exactly as given.\n")
+	return b.String(), nil
+}
@@ -0,0 +1,96 @@
+package parser
+
+import (
+	"strings"
+
+	"goscaffold/internal/models"
+)
+
+const (
+	docSep    = "---"
+	langToken = "lang:"
+)
+
+// parseYAMLStyle extracts files written as front-matter documents:
+//
+//	---
+//	path: cmd/main.go
+//	lang: go
+//	---
+//	package main
+//
+// A header may also carry "action: delete", or "action: rename" with a
+// "to: <path>" line; the body is then ignored.
+//
+// A file's body runs until the next header or the end of the input, so "---"
+// lines inside a body are kept unless a path: line follows them.
+func parseYAMLStyle(content string) []models.File {
+	lines := strings.Split(content, "\n")
+
+	var (
+		files []models.File
+		cur   *models.File
+		code  strings.Builder
+	)
+	flush := func(end int) {
+		if cur != nil {
+			cur.Code = blockBody(code.String())
+			cur.EndLine = end
+			files = append(files, *cur)
+			cur = nil
+		}
+	}
+
+	for i := 0; i < len(lines); i++ {
+		if !isHeaderStart(lines, i) {
+			if cur != nil {
+				code.WriteString(lines[i])
+				code.WriteByte('\n')
+			}
+			continue
+		}
+
+		flush(i)
+		cur = &models.File{StartLine: i + 1}
+		code.Reset()
+
+		// Header fields run until the closing separator.
+		for i++; i < len(lines); i++ {
+			trimmed := strings.TrimSpace(lines[i])
+			if trimmed == docSep {
+				break
+			}
+			if p, ok := strings.CutPrefix(trimmed, pathToken); ok {
+				cur.Path = strings.TrimSpace(p)
+			} else if l, ok := strings.CutPrefix(trimmed, langToken); ok {
+				cur.Language = strings.TrimSpace(l)
+			} else if a, ok := strings.CutPrefix(trimmed, actionToken); ok {
+				action, err := models.ParseAction(strings.TrimSpace(a))
+				if err != nil {
+					cur.Warnings = append(cur.Warnings, err.Error())
+				}
+				cur.Action = action
+			} else if to, ok := strings.CutPrefix(trimmed, toToken); ok {
+				cur.NewPath = strings.TrimSpace(to)
+			} else if e, ok := strings.CutPrefix(trimmed, encodingToken); ok {
+				cur.Encoding = strings.ToLower(strings.TrimSpace(e))
+			}
+		}
+	}
+	// A trailing newline splits off an empty last "line" that is not in the
+	// input.
+	last := len(lines)
+	if lines[last-1] == "" {
+		last--
+	}
+	flush(last)
+
+	return files
+}
+
+// isHeaderStart reports whether lines[i] opens a front-matter header.
+func isHeaderStart(lines []string, i int) bool {
+	return strings.TrimSpace(lines[i]) == docSep &&
+		i+1 < len(lines) &&
+		strings.HasPrefix(strings.TrimSpace(lines[i+1]), pathToken)
+}
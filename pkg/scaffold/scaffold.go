@@ -0,0 +1,508 @@
+package scaffold
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"golang.org/x/sync/errgroup"
+
+	"goscaffold/internal/models"
+	"goscaffold/pkg/backup"
+	"goscaffold/pkg/formatter"
+	"goscaffold/pkg/fsutil"
+	"goscaffold/pkg/merge"
+	"goscaffold/pkg/parser"
+	"goscaffold/pkg/stats"
+	"goscaffold/pkg/validator"
+)
+
+// Conflict policies for files that exist with different content.
+const (
+	ConflictOverwrite = "overwrite"
+	ConflictSkip      = "skip"
+	ConflictBackup    = "backup"
+	ConflictMerge     = "merge"
+)
+
+// Validation scopes.
+const (
+	// ScopeFile validates each file before writing it.
+	ScopeFile = "file"
+	// ScopePackage validates each directory's files together once all are
+	// written; see Importer.ValidatePackages.
+	ScopePackage = "package"
+)
+
+// DefaultConcurrency is used when Options.Concurrency is not positive.
+const DefaultConcurrency = 4
+
+// Options configures an import. The zero value writes into the working
+// directory, overwrites files that differ, validates each file with
+// failures as warnings, and backs up only when the conflict policy asks.
+type Options struct {
+	// Dir is prepended to every file path; empty means the working directory.
+	Dir string
+
+	// Parser configures Import's parsing of its content.
+	Parser parser.Options
+
+	// Concurrency is how many files Write handles at once.
+	Concurrency int
+
+	// Backup stores backups; nil uses a backup.Dir under Dir. BackupAll backs
+	// up every file before replacing it with different content, not only for
+	// the backup and merge conflict policies.
+	Backup    *backup.Manager
+	BackupAll bool
+
+	// Journal, when set, records created files and backups for undo.
+	Journal *backup.Session
+
+	// OnConflict is one of the Conflict constants; empty means overwrite.
+	OnConflict string
+
+	// ValidateScope is ScopeFile (the default) or ScopePackage. Strict makes
+	// every validation failure block the write, as validators' own strict
+	// setting does; otherwise failures are logged as warnings.
+	ValidateScope string
+	Strict        bool
+
+	// MaxFileSize skips files with more bytes of code; 0 is unlimited.
+	MaxFileSize int64
+
+	// Force rewrites files whose content is unchanged.
+	Force bool
+
+	// Format runs the configured formatter on each written file.
+	Format bool
+
+	// AllowDelete applies delete and rename actions; otherwise they are
+	// skipped.
+	AllowDelete bool
+
+	// OnFile, when set, is called after each file is handled, with whether
+	// it was written (or deleted or renamed). Write calls it from several
+	// goroutines at once.
+	OnFile func(f models.File, written bool)
+}
+
+// Importer writes parsed files according to its Options.
+type Importer struct {
+	opts Options
+}
+
+func New(opts Options) *Importer {
+	if opts.Backup == nil {
+		opts.Backup = backup.NewManager(filepath.Join(opts.Dir, backup.Dir), "", 0)
+	}
+	if opts.Journal == nil {
+		opts.Journal = backup.NewSession()
+	}
+	if opts.OnConflict == "" {
+		opts.OnConflict = ConflictOverwrite
+	}
+	if opts.ValidateScope == "" {
+		opts.ValidateScope = ScopeFile
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultConcurrency
+	}
+	return &Importer{opts: opts}
+}
+
+// Import parses content and writes the files it describes, returning the
+// statistics of the run. Files whose path is invalid are dropped with a
+// warning. Diffs in content are applied to the files under opts.Dir unless
+// opts.Parser.ReadFile is set.
+func Import(ctx context.Context, content string, opts Options) (*stats.Stats, error) {
+	popts := opts.Parser
+	popts.DropInvalid = true
+	// Patches apply to the files they will be written over, under Dir.
+	if popts.ReadFile == nil && opts.Dir != "" {
+		popts.ReadFile = func(p string) ([]byte, error) {
+			return os.ReadFile(filepath.Join(opts.Dir, p))
+		}
+	}
+	files, dropped := parser.ParseWithOptions(content, popts)
+	for _, f := range dropped {
+		log.Warn("Skipping file", "path", f.Path, "line", f.StartLine, "reason", strings.Join(f.Warnings, "; "))
+	}
+
+	im := New(opts)
+	s := stats.New()
+	written, err := im.Write(ctx, files, s)
+	if err != nil {
+		return s, err
+	}
+	if err := im.ValidatePackages(ctx, written); err != nil {
+		return s, err
+	}
+	if s.Failed > 0 {
+		return s, fmt.Errorf("%d file(s) failed validation", s.Failed)
+	}
+	return s, nil
+}
+
+// Write handles files concurrently, recording outcomes in s, and returns
+// the files that were written. Once ctx is cancelled no new files are
+// started; those left are recorded as skipped and ctx's error is returned
+// after in-flight files finish.
+func (im *Importer) Write(ctx context.Context, files []models.File, s *stats.Stats) ([]models.File, error) {
+	files = im.resolve(files)
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(im.opts.Concurrency)
+
+	var (
+		mu        sync.Mutex
+		written   []models.File
+		cancelled int
+	)
+	skipCancelled := func(path string) {
+		s.AddSkipped(path)
+		mu.Lock()
+		cancelled++
+		mu.Unlock()
+	}
+
+	for i, file := range files {
+		// Stop handing out files once cancelled; in-flight writes finish.
+		if gctx.Err() != nil {
+			for _, rest := range files[i:] {
+				skipCancelled(rest.Path)
+			}
+			break
+		}
+		f := file
+		g.Go(func() error {
+			select {
+			case <-gctx.Done():
+				skipCancelled(f.Path)
+				return nil
+			default:
+			}
+
+			ok, err := im.writeFile(gctx, f, s)
+			if ok {
+				mu.Lock()
+				written = append(written, f)
+				mu.Unlock()
+			}
+			if im.opts.OnFile != nil {
+				im.opts.OnFile(f, ok)
+			}
+			return err
+		})
+	}
+
+	err := g.Wait()
+	if ctx.Err() != nil {
+		log.Warn(fmt.Sprintf("Skipped %d file(s) after cancellation", cancelled))
+		return written, ctx.Err()
+	}
+	return written, err
+}
+
+// WriteFile handles a single file, reporting whether it was written.
+func (im *Importer) WriteFile(ctx context.Context, file models.File, s *stats.Stats) (bool, error) {
+	return im.writeFile(ctx, im.resolve([]models.File{file})[0], s)
+}
+
+// resolve places files under Dir.
+func (im *Importer) resolve(files []models.File) []models.File {
+	if im.opts.Dir == "" {
+		return files
+	}
+	out := make([]models.File, len(files))
+	for i, f := range files {
+		f.Path = filepath.Join(im.opts.Dir, f.Path)
+		if f.NewPath != "" {
+			f.NewPath = filepath.Join(im.opts.Dir, f.NewPath)
+		}
+		out[i] = f
+	}
+	return out
+}
+
+func (im *Importer) writeFile(ctx context.Context, file models.File, s *stats.Stats) (bool, error) {
+	opts := im.opts
+	bm, j := opts.Backup, opts.Journal
+	start := time.Now()
+
+	if !file.Writes() {
+		return im.applyAction(file, s)
+	}
+
+	if opts.MaxFileSize > 0 && int64(len(file.Code)) > opts.MaxFileSize {
+		log.Warn("Skipping oversized file", "path", file.Path, "size", len(file.Code), "max", opts.MaxFileSize)
+		s.AddSkipped(file.Path)
+		return false, nil
+	}
+
+	same, err := fsutil.SameContent(file.Path, []byte(file.Code))
+	existed := err == nil
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return false, fmt.Errorf("read %s: %w", file.Path, err)
+	}
+	if same && !opts.Force {
+		im.saveBase(file)
+		s.AddUnchanged(file.Path)
+		return false, nil
+	}
+	conflict := existed && !same
+
+	code := file.Code
+	if conflict {
+		switch opts.OnConflict {
+		case ConflictSkip:
+			log.Info("Skipping existing file", "path", file.Path)
+			s.AddSkipped(file.Path)
+			return false, nil
+		case ConflictMerge:
+			existing, err := os.ReadFile(file.Path)
+			if err != nil {
+				return false, fmt.Errorf("read %s: %w", file.Path, err)
+			}
+			code = mergeFile(bm, file.Path, string(existing), file.Code)
+		}
+	}
+
+	// In package scope ValidatePackages checks the written files afterwards.
+	validated := false
+	if opts.ValidateScope != ScopePackage {
+		if v, err := validator.GetForFile(file.TypedPath()); err == nil {
+			err := v.Validate(ctx, file.TypedPath(), code)
+			switch {
+			case err == nil:
+				validated = true
+			case ctx.Err() != nil:
+				return false, ctx.Err()
+			case opts.Strict || v.Strict:
+				log.Error("Validation failed, not writing", "file", file.Path, "error", err)
+				s.AddFailed(file.Path)
+				return false, nil
+			case errors.Is(err, validator.ErrTimeout):
+				log.Warn("Validator timed out", "file", file.Path, "error", err)
+			default:
+				log.Warn("Validation warning", "file", file.Path, "error", err)
+			}
+		}
+	}
+
+	// A file being rewritten with its own content (Force) is not backed up.
+	var backupPath string
+	if conflict && (opts.BackupAll || opts.OnConflict == ConflictBackup || opts.OnConflict == ConflictMerge) {
+		if dst, err := bm.Backup(file.Path); err != nil {
+			log.Warn("Backup failed", "file", file.Path, "error", err)
+		} else if dst != "" {
+			backupPath = dst
+			j.AddBackup(file.Path, dst)
+			log.Debug("Backed up file", "path", file.Path, "backup", dst)
+		}
+	}
+
+	dir := filepath.Dir(file.Path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return false, fmt.Errorf("mkdir %s: %w", dir, err)
+	}
+
+	if err := fsutil.WriteAtomic(file.Path, []byte(code), 0644); err != nil {
+		return false, fmt.Errorf("write %s: %w", file.Path, err)
+	}
+	im.saveBase(file)
+
+	if opts.Format {
+		if f, err := formatter.Get(file.Path); err == nil {
+			if err := f.Format(ctx, file.Path); err != nil {
+				log.Warn("Formatting failed", "file", file.Path, "error", err)
+			}
+		}
+	}
+
+	s.AddTiming(file.Path, time.Since(start))
+	fileStat := stats.FileStat{
+		Path:       file.Path,
+		Bytes:      len(code),
+		Language:   strings.TrimPrefix(file.Ext(), "."),
+		BackupPath: backupPath,
+		Validated:  validated,
+	}
+	if existed {
+		s.AddOverwritten(fileStat)
+		log.Debug("Overwrote file", "path", file.Path, "size", len(code))
+	} else {
+		j.AddCreated(file.Path)
+		s.AddFile(fileStat)
+		log.Debug("Created file", "path", file.Path, "size", len(code))
+	}
+	return true, nil
+}
+
+// applyAction deletes or renames file.Path. The original is backed up first
+// and journaled so undo can bring it back.
+func (im *Importer) applyAction(file models.File, s *stats.Stats) (bool, error) {
+	bm, j := im.opts.Backup, im.opts.Journal
+
+	if !im.opts.AllowDelete {
+		log.Warn(fmt.Sprintf("Not applying %s without --allow-delete", file.Action), "path", file.Path)
+		s.AddSkipped(file.Path)
+		return false, nil
+	}
+
+	if _, err := os.Stat(file.Path); errors.Is(err, fs.ErrNotExist) {
+		log.Warn(fmt.Sprintf("Nothing to %s", file.Action), "path", file.Path)
+		s.AddSkipped(file.Path)
+		return false, nil
+	}
+	if file.Action == models.ActionRename {
+		if _, err := os.Stat(file.NewPath); err == nil {
+			log.Error("Rename target already exists", "path", file.Path, "to", file.NewPath)
+			s.AddFailed(file.Path)
+			return false, nil
+		}
+	}
+
+	backupPath, err := bm.Backup(file.Path)
+	if err != nil {
+		return false, fmt.Errorf("backup %s: %w", file.Path, err)
+	}
+	j.AddBackup(file.Path, backupPath)
+	fileStat := stats.FileStat{Path: file.Path, BackupPath: backupPath}
+
+	switch file.Action {
+	case models.ActionDelete:
+		if err := os.Remove(file.Path); err != nil {
+			return false, fmt.Errorf("delete %s: %w", file.Path, err)
+		}
+		log.Info("Deleted", "path", file.Path)
+		s.AddDeleted(fileStat)
+	case models.ActionRename:
+		if err := os.MkdirAll(filepath.Dir(file.NewPath), 0755); err != nil {
+			return false, fmt.Errorf("mkdir %s: %w", filepath.Dir(file.NewPath), err)
+		}
+		if err := os.Rename(file.Path, file.NewPath); err != nil {
+			return false, fmt.Errorf("rename %s: %w", file.Path, err)
+		}
+		j.AddCreated(file.NewPath)
+		log.Info("Renamed", "path", file.Path, "to", file.NewPath)
+		fileStat.NewPath = file.NewPath
+		s.AddRenamed(fileStat)
+	}
+	return true, nil
+}
+
+// saveBase records the imported content of file, before any merge, as the
+// base for merging the next import of it. Bases are kept only when the
+// import merges or takes backups; a plain overwrite leaves the backup
+// directory alone.
+func (im *Importer) saveBase(file models.File) {
+	opts := im.opts
+	if !opts.BackupAll && opts.OnConflict != ConflictMerge && opts.OnConflict != ConflictBackup {
+		return
+	}
+	if err := im.opts.Backup.SaveBase(file.Path, []byte(file.Code)); err != nil {
+		log.Warn("Recording merge base failed", "file", file.Path, "error", err)
+	}
+}
+
+// mergeFile three-way merges incoming into current, using the content last
+// imported to path as the base. That way only what the user changed since
+// is weighed against the new content. Without a recorded import the latest
+// backup of path is the base; without either the whole file differs from
+// the empty base, so any difference is a conflict.
+func mergeFile(bm *backup.Manager, path, current, incoming string) string {
+	base, ok, err := bm.Base(path)
+	if err != nil {
+		log.Warn("Reading merge base failed", "file", path, "error", err)
+	}
+	if !ok {
+		base = latestBackup(bm, path)
+	}
+
+	merged, conflicted := merge.ThreeWay(base, current, incoming)
+	if conflicted {
+		log.Warn("Merge conflicts written with markers", "file", path)
+	}
+	return merged
+}
+
+// latestBackup returns the content of the most recent backup of path, or ""
+// if there is none.
+func latestBackup(bm *backup.Manager, path string) string {
+	e, ok, err := bm.Latest(path)
+	if err != nil {
+		log.Warn("Listing backups failed", "file", path, "error", err)
+		return ""
+	}
+	if !ok {
+		log.Debug("No earlier import or backup to use as merge base", "file", path)
+		return ""
+	}
+	data, err := os.ReadFile(e.Path)
+	if err != nil {
+		log.Warn("Reading backup failed", "file", path, "backup", e.Path, "error", err)
+		return ""
+	}
+	return string(data)
+}
+
+// ValidatePackages implements ScopePackage: once everything is written,
+// each directory's files are handed to their validator together. Files are
+// already on disk, so failures cannot block writes; strict failures are
+// returned so callers can report them and offer undo. In ScopeFile it does
+// nothing.
+func (im *Importer) ValidatePackages(ctx context.Context, files []models.File) error {
+	if im.opts.ValidateScope != ScopePackage {
+		return nil
+	}
+
+	type group struct {
+		dir string
+		v   *validator.Validator
+	}
+	var order []group
+	groups := make(map[group][]string)
+	for _, f := range files {
+		if !f.Writes() {
+			continue
+		}
+		v, err := validator.GetForFile(f.TypedPath())
+		if err != nil {
+			continue
+		}
+		g := group{filepath.Dir(f.Path), v}
+		if _, ok := groups[g]; !ok {
+			order = append(order, g)
+		}
+		groups[g] = append(groups[g], f.Path)
+	}
+
+	failed := 0
+	for _, g := range order {
+		err := g.v.ValidateFiles(ctx, groups[g])
+		switch {
+		case err == nil:
+			log.Debug("Package validated", "dir", g.dir, "files", len(groups[g]))
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case im.opts.Strict || g.v.Strict:
+			log.Error("Package validation failed", "dir", g.dir, "error", err)
+			failed++
+		default:
+			log.Warn("Package validation warning", "dir", g.dir, "error", err)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d package(s) failed validation", failed)
+	}
+	return nil
+}
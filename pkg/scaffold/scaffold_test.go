@@ -0,0 +1,382 @@
+package scaffold
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"goscaffold/internal/models"
+	"goscaffold/pkg/backup"
+	"goscaffold/pkg/stats"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// importFile writes one file with a fresh Importer over dir.
+func importFile(t *testing.T, opts Options, path, code string) *stats.Stats {
+	t.Helper()
+	s := stats.New()
+	if _, err := New(opts).Write(context.Background(), []models.File{{Path: path, Code: code}}, s); err != nil {
+		t.Fatal(err)
+	}
+	return s
+}
+
+func TestMergeUsesLastImport(t *testing.T) {
+	tests := []struct {
+		name     string
+		edited   string
+		incoming string
+		want     string
+		conflict bool
+	}{
+		{
+			name:     "clean",
+			edited:   "USER\nb\nc\nd\ne\n",
+			incoming: "a\nb\nc\nd\nNEW\n",
+			want:     "USER\nb\nc\nd\nNEW\n",
+		},
+		{
+			name:     "conflict",
+			edited:   "a\nb\nUSER\nd\ne\n",
+			incoming: "a\nb\nNEW\nd\ne\n",
+			want:     "a\nb\n<<<<<<< current\nUSER\n=======\nNEW\n>>>>>>> incoming\nd\ne\n",
+			conflict: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			opts := Options{Dir: dir, OnConflict: ConflictMerge}
+			path := filepath.Join(dir, "notes.txt")
+
+			importFile(t, opts, "notes.txt", "a\nb\nc\nd\ne\n")
+			writeTestFile(t, path, tt.edited)
+			importFile(t, opts, "notes.txt", tt.incoming)
+
+			got := readTestFile(t, path)
+			if got != tt.want {
+				t.Errorf("merged:\n%s\nwant:\n%s", got, tt.want)
+			}
+			if strings.Contains(got, "<<<<<<<") != tt.conflict {
+				t.Errorf("conflict markers = %v, want %v", !tt.conflict, tt.conflict)
+			}
+		})
+	}
+}
+
+func TestMergeKeepsEditsAcrossImports(t *testing.T) {
+	dir := t.TempDir()
+	opts := Options{Dir: dir, OnConflict: ConflictMerge}
+	path := filepath.Join(dir, "notes.txt")
+
+	importFile(t, opts, "notes.txt", "a\nb\nc\nd\ne\n")
+	writeTestFile(t, path, "USER\nb\nc\nd\ne\n")
+	importFile(t, opts, "notes.txt", "a\nb\nc\nd\nTWO\n")
+	// The merge base is now the second import, not the user's file from
+	// before it, so the edit to the first line is not reverted.
+	importFile(t, opts, "notes.txt", "a\nb\nc\nd\nTHREE\n")
+
+	if got, want := readTestFile(t, path), "USER\nb\nc\nd\nTHREE\n"; got != want {
+		t.Errorf("merged:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestOverwriteRecordsNoBase(t *testing.T) {
+	dir := t.TempDir()
+	bm := backup.NewManager(filepath.Join(dir, "backups"), "", 0)
+	opts := Options{Dir: dir, Backup: bm}
+
+	importFile(t, opts, "notes.txt", "one\n")
+	importFile(t, opts, "notes.txt", "two\n")
+	importFile(t, opts, "notes.txt", "two\n")
+
+	if _, err := os.Stat(bm.Dir()); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("overwrite import created %s (err %v)", bm.Dir(), err)
+	}
+}
+
+func TestMergeFallsBackToLatestBackup(t *testing.T) {
+	dir := t.TempDir()
+	bm := backup.NewManager(filepath.Join(dir, "backups"), "", 0)
+	path := filepath.Join(dir, "notes.txt")
+
+	// A backup from before bases were recorded stands in for the last import.
+	writeTestFile(t, path, "a\nb\nc\nd\ne\n")
+	if _, err := bm.Backup(path); err != nil {
+		t.Fatal(err)
+	}
+	writeTestFile(t, path, "USER\nb\nc\nd\ne\n")
+	importFile(t, Options{Dir: dir, Backup: bm, OnConflict: ConflictMerge}, "notes.txt", "a\nb\nc\nd\nNEW\n")
+
+	if got, want := readTestFile(t, path), "USER\nb\nc\nd\nNEW\n"; got != want {
+		t.Errorf("merged:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestImportPatchUnderDir(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(t.TempDir())
+	writeTestFile(t, filepath.Join(dir, "notes.txt"), "a\nb\nc\n")
+
+	patch := "```diff\n--- a/notes.txt\n+++ b/notes.txt\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n```\n"
+	if _, err := Import(context.Background(), patch, Options{Dir: dir}); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := readTestFile(t, filepath.Join(dir, "notes.txt")), "a\nB\nc\n"; got != want {
+		t.Errorf("notes.txt = %q, want %q", got, want)
+	}
+	if _, err := os.Stat("notes.txt"); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("patch touched the working directory (err %v)", err)
+	}
+}
+
+func TestWriteConcurrencyLimit(t *testing.T) {
+	for _, n := range []int{1, 3} {
+		t.Run(fmt.Sprint(n), func(t *testing.T) {
+			var (
+				mu           sync.Mutex
+				active, peak int
+				order        []string
+			)
+			opts := Options{
+				Dir:         t.TempDir(),
+				Concurrency: n,
+				// OnFile runs in the file's slot, so it holds the slot
+				// while it sleeps.
+				OnFile: func(f models.File, written bool) {
+					mu.Lock()
+					active++
+					peak = max(peak, active)
+					order = append(order, filepath.Base(f.Path))
+					mu.Unlock()
+
+					time.Sleep(10 * time.Millisecond)
+
+					mu.Lock()
+					active--
+					mu.Unlock()
+				},
+			}
+
+			var files []models.File
+			var want []string
+			for i := range 12 {
+				name := fmt.Sprintf("f%02d.txt", i)
+				files = append(files, models.File{Path: name, Code: name + "\n"})
+				want = append(want, name)
+			}
+			if _, err := New(opts).Write(context.Background(), files, stats.New()); err != nil {
+				t.Fatal(err)
+			}
+
+			if peak > n {
+				t.Errorf("%d files in flight, want at most %d", peak, n)
+			}
+			if n > 1 && peak < 2 {
+				t.Errorf("%d files in flight, want files handled in parallel", peak)
+			}
+			if n == 1 && !slices.Equal(order, want) {
+				t.Errorf("order = %v, want input order", order)
+			}
+		})
+	}
+}
+
+func manyFiles(n int) []models.File {
+	files := make([]models.File, n)
+	for i := range files {
+		name := fmt.Sprintf("f%04d.txt", i)
+		files[i] = models.File{Path: name, Code: name + "\n"}
+	}
+	return files
+}
+
+func TestWriteCancelled(t *testing.T) {
+	dir := t.TempDir()
+	files := manyFiles(500)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	s := stats.New()
+	written, err := New(Options{Dir: dir}).Write(ctx, files, s)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Write error = %v, want context.Canceled", err)
+	}
+	if len(written) != 0 || s.Created != 0 {
+		t.Errorf("wrote %d files (created %d) after cancellation", len(written), s.Created)
+	}
+	if s.Skipped != len(files) {
+		t.Errorf("skipped %d files, want %d", s.Skipped, len(files))
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("%d entries written to %s", len(entries), dir)
+	}
+}
+
+func TestWriteCancelledMidway(t *testing.T) {
+	files := manyFiles(500)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var once sync.Once
+	opts := Options{
+		Dir:         t.TempDir(),
+		Concurrency: 2,
+		OnFile: func(models.File, bool) {
+			once.Do(cancel)
+		},
+	}
+
+	s := stats.New()
+	written, err := New(opts).Write(ctx, files, s)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Write error = %v, want context.Canceled", err)
+	}
+	// Files already in flight finish; every other file is skipped.
+	if len(written) == 0 || len(written) > opts.Concurrency {
+		t.Errorf("wrote %d files, want 1 to %d", len(written), opts.Concurrency)
+	}
+	if s.Created+s.Skipped != len(files) {
+		t.Errorf("created %d + skipped %d, want %d files accounted for", s.Created, s.Skipped, len(files))
+	}
+}
+
+func TestWriteActions(t *testing.T) {
+	dir := t.TempDir()
+	at := func(rel string) string { return filepath.Join(dir, rel) }
+	writeTestFile(t, at("main.txt"), "old main\n")
+	writeTestFile(t, at("old.txt"), "to delete\n")
+	writeTestFile(t, at("a.txt"), "to rename\n")
+
+	j := backup.NewSession()
+	s := stats.New()
+	files := []models.File{
+		{Path: "new.txt", Code: "created\n", Action: models.ActionCreate},
+		{Path: "main.txt", Code: "new main\n", Action: models.ActionUpdate},
+		{Path: "old.txt", Action: models.ActionDelete},
+		{Path: "a.txt", Action: models.ActionRename, NewPath: "sub/b.txt"},
+	}
+	written, err := New(Options{Dir: dir, AllowDelete: true, Journal: j, Concurrency: 1}).Write(context.Background(), files, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(written) != len(files) {
+		t.Errorf("wrote %d files, want %d", len(written), len(files))
+	}
+
+	if got := readTestFile(t, at("new.txt")); got != "created\n" {
+		t.Errorf("new.txt = %q", got)
+	}
+	if got := readTestFile(t, at("main.txt")); got != "new main\n" {
+		t.Errorf("main.txt = %q", got)
+	}
+	if _, err := os.Stat(at("old.txt")); !os.IsNotExist(err) {
+		t.Error("old.txt still exists")
+	}
+	if _, err := os.Stat(at("a.txt")); !os.IsNotExist(err) {
+		t.Error("a.txt still exists after rename")
+	}
+	if got := readTestFile(t, at("sub/b.txt")); got != "to rename\n" {
+		t.Errorf("sub/b.txt = %q", got)
+	}
+
+	if s.Created != 1 || s.Overwritten != 1 || s.Deleted != 1 || s.Renamed != 1 {
+		t.Errorf("stats created=%d overwritten=%d deleted=%d renamed=%d, want 1 each", s.Created, s.Overwritten, s.Deleted, s.Renamed)
+	}
+
+	// Deleted and renamed originals are backed up so undo can restore them.
+	backedUp := make(map[string]string)
+	for _, b := range j.Backups {
+		backedUp[b.Original] = readTestFile(t, b.Path)
+	}
+	if backedUp[at("old.txt")] != "to delete\n" || backedUp[at("a.txt")] != "to rename\n" {
+		t.Errorf("backups = %v, want old.txt and a.txt", backedUp)
+	}
+	if !slices.Contains(j.Created, at("sub/b.txt")) || !slices.Contains(j.Created, at("new.txt")) {
+		t.Errorf("journal created = %v, want new.txt and sub/b.txt", j.Created)
+	}
+}
+
+func TestWriteActionsNeedAllowDelete(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "old.txt"), "keep\n")
+	writeTestFile(t, filepath.Join(dir, "a.txt"), "keep\n")
+
+	s := stats.New()
+	files := []models.File{
+		{Path: "old.txt", Action: models.ActionDelete},
+		{Path: "a.txt", Action: models.ActionRename, NewPath: "b.txt"},
+	}
+	written, err := New(Options{Dir: dir}).Write(context.Background(), files, s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(written) != 0 || s.Skipped != 2 {
+		t.Errorf("wrote %d, skipped %d; want 0 and 2", len(written), s.Skipped)
+	}
+	for _, name := range []string{"old.txt", "a.txt"} {
+		if got := readTestFile(t, filepath.Join(dir, name)); got != "keep\n" {
+			t.Errorf("%s = %q, want it untouched", name, got)
+		}
+	}
+}
+
+func TestBackupOnlyWhenContentDiffers(t *testing.T) {
+	dir := t.TempDir()
+	writeTestFile(t, filepath.Join(dir, "same.txt"), "same\n")
+	writeTestFile(t, filepath.Join(dir, "diff.txt"), "before\n")
+
+	bm := backup.NewManager(filepath.Join(dir, "backups"), "", 0)
+	opts := Options{Dir: dir, Backup: bm, BackupAll: true, Force: true}
+	files := []models.File{
+		{Path: "same.txt", Code: "same\n"},
+		{Path: "diff.txt", Code: "after\n"},
+	}
+	s := stats.New()
+	if _, err := New(opts).Write(context.Background(), files, s); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := bm.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].Original != filepath.Join(dir, "diff.txt") {
+		t.Fatalf("backups = %+v, want only diff.txt", entries)
+	}
+	if got := readTestFile(t, entries[0].Path); got != "before\n" {
+		t.Errorf("backup holds %q, want the old content", got)
+	}
+	for _, f := range s.Files {
+		if f.Path == filepath.Join(dir, "same.txt") && f.BackupPath != "" {
+			t.Errorf("same.txt reported backup %s", f.BackupPath)
+		}
+	}
+}
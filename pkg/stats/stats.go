@@ -1,41 +1,327 @@
 package stats
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 	"path/filepath"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/charmbracelet/log"
 )
 
+// Stats is safe for concurrent use; mu guards every field below it.
 type Stats struct {
-	TotalFiles int
-	TotalBytes int
-	Languages  map[string]int
+	// OnRecord, when set, is called with each file record as it is added,
+	// with mu held. Set it before the Stats is shared.
+	OnRecord func(FileStat)
+
+	mu sync.Mutex
+
+	TotalFiles  int
+	TotalBytes  int
+	Created     int
+	Overwritten int
+	Skipped     int
+	Unchanged   int
+	Deleted     int
+	Renamed     int
+	Failed      int
+	Languages   map[string]int
+
+	// Files holds one record per file, in the order they were recorded.
+	Files []FileStat
+
+	// Start is when the run began; the zero value disables timing output.
+	Start   time.Time
+	Timings []FileTiming
+}
+
+// FileStat describes what happened to a single file.
+type FileStat struct {
+	Path       string `json:"path"`
+	NewPath    string `json:"new_path,omitempty"`
+	Action     string `json:"action"`
+	Bytes      int    `json:"bytes"`
+	Language   string `json:"language,omitempty"`
+	BackupPath string `json:"backup_path,omitempty"`
+	Validated  bool   `json:"validated"`
+}
+
+const (
+	ActionCreated     = "created"
+	ActionOverwritten = "overwritten"
+	ActionUnchanged   = "unchanged"
+	ActionDeleted     = "deleted"
+	ActionRenamed     = "renamed"
+	ActionSkipped     = "skipped"
+	ActionFailed      = "failed"
+)
+
+type FileTiming struct {
+	Path     string
+	Duration time.Duration
 }
 
 func New() *Stats {
 	return &Stats{
 		Languages: make(map[string]int),
+		Start:     time.Now(),
+	}
+}
+
+// AddFile records a newly created file.
+func (s *Stats) AddFile(f FileStat) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Created++
+	f.Action = ActionCreated
+	s.add(f)
+}
+
+// AddOverwritten records a write that replaced an existing file.
+func (s *Stats) AddOverwritten(f FileStat) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Overwritten++
+	f.Action = ActionOverwritten
+	s.add(f)
+}
+
+// AddDeleted records a deleted file.
+func (s *Stats) AddDeleted(f FileStat) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Deleted++
+	f.Action = ActionDeleted
+	s.record(f)
+	log.Debug("Deleted file", "path", f.Path)
+}
+
+// AddRenamed records a file moved from Path to NewPath.
+func (s *Stats) AddRenamed(f FileStat) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Renamed++
+	f.Action = ActionRenamed
+	s.record(f)
+	log.Debug("Renamed file", "path", f.Path, "to", f.NewPath)
+}
+
+func (s *Stats) AddSkipped(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Skipped++
+	s.record(FileStat{Path: path, Action: ActionSkipped})
+	log.Debug("Skipped file", "path", path)
+}
+
+// AddUnchanged records a file that already had the imported content.
+func (s *Stats) AddUnchanged(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Unchanged++
+	s.record(FileStat{Path: path, Action: ActionUnchanged})
+	log.Debug("Unchanged file", "path", path)
+}
+
+// AddFailed records a file that was not written because it failed.
+func (s *Stats) AddFailed(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Failed++
+	s.record(FileStat{Path: path, Action: ActionFailed})
+	log.Debug("Failed file", "path", path)
+}
+
+func (s *Stats) AddTiming(path string, d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Timings = append(s.Timings, FileTiming{Path: path, Duration: d})
+}
+
+// Slowest returns up to n timings, slowest first.
+func (s *Stats) Slowest(n int) []FileTiming {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sorted := append([]FileTiming(nil), s.Timings...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Duration > sorted[j].Duration
+	})
+	if len(sorted) > n {
+		sorted = sorted[:n]
 	}
+	return sorted
 }
 
-func (s *Stats) AddFile(path, code string) {
+func (s *Stats) Elapsed() time.Duration {
+	if s.Start.IsZero() {
+		return 0
+	}
+	return time.Since(s.Start)
+}
+
+// add must be called with s.mu held.
+func (s *Stats) add(f FileStat) {
 	s.TotalFiles++
-	s.TotalBytes += len(code)
+	s.TotalBytes += f.Bytes
+
+	if f.Language == "" {
+		f.Language = strings.TrimPrefix(filepath.Ext(f.Path), ".")
+	}
+	if f.Language == "" {
+		f.Language = "unknown"
+	}
+	s.Languages[f.Language]++
+
+	s.record(f)
+}
 
-	ext := strings.TrimPrefix(filepath.Ext(path), ".")
-	if ext == "" {
-		ext = "unknown"
+// record must be called with s.mu held.
+func (s *Stats) record(f FileStat) {
+	s.Files = append(s.Files, f)
+	if s.OnRecord != nil {
+		s.OnRecord(f)
 	}
-	s.Languages[ext]++
 }
 
-func (s *Stats) Print() {
+// Largest returns up to n written files, largest first.
+func (s *Stats) Largest(n int) []FileStat {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var written []FileStat
+	for _, f := range s.Files {
+		if f.Action == ActionCreated || f.Action == ActionOverwritten {
+			written = append(written, f)
+		}
+	}
+	sort.SliceStable(written, func(i, j int) bool {
+		return written[i].Bytes > written[j].Bytes
+	})
+	if len(written) > n {
+		written = written[:n]
+	}
+	return written
+}
+
+// Print logs the statistics. The language histogram is ordered by count,
+// then name, and capped at top entries when top > 0.
+func (s *Stats) Print(top int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	log.Info("=== Statistics ===")
-	log.Info(fmt.Sprintf("Files: %d", s.TotalFiles))
+	log.Info(fmt.Sprintf("Files: %d (created %d, overwritten %d, unchanged %d, skipped %d, failed %d)",
+		s.TotalFiles, s.Created, s.Overwritten, s.Unchanged, s.Skipped, s.Failed))
+	if s.Deleted > 0 || s.Renamed > 0 {
+		log.Info(fmt.Sprintf("Deleted: %d, renamed: %d", s.Deleted, s.Renamed))
+	}
 	log.Info(fmt.Sprintf("Bytes: %d", s.TotalBytes))
-	for lang, count := range s.Languages {
-		log.Info(fmt.Sprintf("  %s: %d", lang, count))
+	if elapsed := s.Elapsed(); elapsed > 0 {
+		log.Info(fmt.Sprintf("Time: %s (%.0f bytes/sec)",
+			elapsed.Round(time.Millisecond), float64(s.TotalBytes)/elapsed.Seconds()))
+	}
+	langs := sortedLanguages(s.Languages)
+	more := 0
+	if top > 0 && len(langs) > top {
+		langs, more = langs[:top], len(langs)-top
+	}
+	for _, l := range langs {
+		log.Info(fmt.Sprintf("  %s: %d", l.Language, l.Count))
+	}
+	if more > 0 {
+		log.Info(fmt.Sprintf("  +%d more", more))
+	}
+}
+
+// LanguageNames returns the recorded languages, most common first.
+func (s *Stats) LanguageNames() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var names []string
+	for _, l := range sortedLanguages(s.Languages) {
+		names = append(names, l.Language)
+	}
+	return names
+}
+
+type languageCount struct {
+	Language string
+	Count    int
+}
+
+// sortedLanguages returns the histogram ordered by descending count, then
+// by name.
+func sortedLanguages(m map[string]int) []languageCount {
+	langs := make([]languageCount, 0, len(m))
+	for lang, count := range m {
+		langs = append(langs, languageCount{lang, count})
+	}
+	sort.Slice(langs, func(i, j int) bool {
+		if langs[i].Count != langs[j].Count {
+			return langs[i].Count > langs[j].Count
+		}
+		return langs[i].Language < langs[j].Language
+	})
+	return langs
+}
+
+// Summary returns the totals as a single line of key=value pairs.
+func (s *Stats) Summary() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return fmt.Sprintf("files=%d created=%d overwritten=%d unchanged=%d deleted=%d renamed=%d skipped=%d failed=%d bytes=%d",
+		s.TotalFiles, s.Created, s.Overwritten, s.Unchanged, s.Deleted, s.Renamed, s.Skipped, s.Failed, s.TotalBytes)
+}
+
+// WriteJSON writes the totals, language counts and per-file records as a
+// single JSON object. Language keys are emitted in sorted order.
+func (s *Stats) WriteJSON(w io.Writer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(struct {
+		TotalFiles  int            `json:"total_files"`
+		TotalBytes  int            `json:"total_bytes"`
+		Created     int            `json:"created"`
+		Overwritten int            `json:"overwritten"`
+		Unchanged   int            `json:"unchanged"`
+		Deleted     int            `json:"deleted"`
+		Renamed     int            `json:"renamed"`
+		Skipped     int            `json:"skipped"`
+		Failed      int            `json:"failed"`
+		Languages   map[string]int `json:"languages"`
+		Files       []FileStat     `json:"files"`
+	}{s.TotalFiles, s.TotalBytes, s.Created, s.Overwritten, s.Unchanged, s.Deleted, s.Renamed, s.Skipped, s.Failed, s.Languages, s.Files})
+}
+
+// WriteManifest writes the per-file records as a JSON array.
+func (s *Stats) WriteManifest(w io.Writer) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	files := s.Files
+	if files == nil {
+		files = []FileStat{}
 	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(files)
 }
@@ -0,0 +1,109 @@
+package stats
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Run with -race: AddFile is called from many goroutines at once by
+// scaffold.Importer.Write.
+func TestAddFileConcurrent(t *testing.T) {
+	s := New()
+	const n = 200
+
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			lang := []string{"go", "py", "ts"}[i%3]
+			s.AddFile(FileStat{Path: fmt.Sprintf("f%d.%s", i, lang), Bytes: 10, Language: lang})
+			if i%10 == 0 {
+				s.AddSkipped(fmt.Sprintf("skip%d", i))
+			}
+		}()
+	}
+	wg.Wait()
+
+	if s.TotalFiles != n || s.Created != n {
+		t.Errorf("TotalFiles = %d, Created = %d, want %d", s.TotalFiles, s.Created, n)
+	}
+	if s.TotalBytes != 10*n {
+		t.Errorf("TotalBytes = %d, want %d", s.TotalBytes, 10*n)
+	}
+	if s.Skipped != n/10 {
+		t.Errorf("Skipped = %d, want %d", s.Skipped, n/10)
+	}
+	if got := s.Languages["go"] + s.Languages["py"] + s.Languages["ts"]; got != n {
+		t.Errorf("language counts sum to %d, want %d", got, n)
+	}
+	if len(s.Files) != n+n/10 {
+		t.Errorf("recorded %d files, want %d", len(s.Files), n+n/10)
+	}
+}
+
+func TestSortedLanguages(t *testing.T) {
+	m := map[string]int{"py": 2, "go": 5, "ts": 2, "md": 1, "c": 2}
+	want := []languageCount{{"go", 5}, {"c", 2}, {"py", 2}, {"ts", 2}, {"md", 1}}
+
+	// Map iteration order varies between runs; the result must not.
+	for range 20 {
+		if got := sortedLanguages(m); !slices.Equal(got, want) {
+			t.Fatalf("sortedLanguages = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestWriteTableLanguages(t *testing.T) {
+	s := New()
+	s.Start = time.Time{}
+	for _, f := range []string{"a.go", "b.go", "c.go", "d.py", "e.ts", "f.py", "g.md"} {
+		lang := strings.TrimPrefix(filepath.Ext(f), ".")
+		s.AddFile(FileStat{Path: f, Language: lang})
+	}
+
+	var buf bytes.Buffer
+	if err := s.WriteTable(&buf, 2, false); err != nil {
+		t.Fatal(err)
+	}
+	_, langs, ok := strings.Cut(buf.String(), "Languages\n")
+	if !ok {
+		t.Fatalf("no Languages section in:\n%s", buf.String())
+	}
+
+	var names []string
+	for _, line := range strings.Split(strings.TrimSpace(langs), "\n") {
+		names = append(names, strings.Fields(line)[0])
+	}
+	if want := []string{"go", "py", "+2"}; !slices.Equal(names, want) {
+		t.Errorf("language rows %v, want %v:\n%s", names, want, langs)
+	}
+}
+
+func TestWriteJSONSnakeCase(t *testing.T) {
+	s := New()
+	s.AddOverwritten(FileStat{Path: "a.go", Bytes: 3, Language: "go", BackupPath: "bak/a.go.backup"})
+	s.AddRenamed(FileStat{Path: "b.go", NewPath: "c.go"})
+
+	var buf bytes.Buffer
+	if err := s.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	for _, key := range []string{`"total_files"`, `"backup_path"`, `"new_path"`} {
+		if !strings.Contains(out, key) {
+			t.Errorf("output has no %s key:\n%s", key, out)
+		}
+	}
+	for _, key := range []string{`"backupPath"`, `"newPath"`} {
+		if strings.Contains(out, key) {
+			t.Errorf("output has camelCase %s key:\n%s", key, out)
+		}
+	}
+}
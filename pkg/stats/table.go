@@ -0,0 +1,113 @@
+package stats
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var (
+	headingStyle = lipgloss.NewStyle().Bold(true)
+	labelStyle   = lipgloss.NewStyle().Faint(true)
+	goodStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("2"))
+	badStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("1"))
+)
+
+// WriteTable writes the statistics as an aligned metric/value table
+// followed by the language breakdown, capped at top entries when top > 0.
+// Without color it is plain text.
+func (s *Stats) WriteTable(w io.Writer, top int, color bool) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	style := func(st lipgloss.Style, text string) string {
+		if !color {
+			return text
+		}
+		return st.Render(text)
+	}
+
+	type row struct {
+		label, value string
+		st           *lipgloss.Style
+	}
+	count := func(label string, n int, st *lipgloss.Style) row {
+		if n == 0 {
+			st = nil
+		}
+		return row{label, strconv.Itoa(n), st}
+	}
+	rows := []row{
+		count("Files", s.TotalFiles, nil),
+		count("Created", s.Created, &goodStyle),
+		count("Overwritten", s.Overwritten, nil),
+		count("Unchanged", s.Unchanged, nil),
+	}
+	if s.Deleted > 0 || s.Renamed > 0 {
+		rows = append(rows, count("Deleted", s.Deleted, nil), count("Renamed", s.Renamed, nil))
+	}
+	rows = append(rows,
+		count("Skipped", s.Skipped, nil),
+		count("Failed", s.Failed, &badStyle),
+		row{"Bytes", strconv.Itoa(s.TotalBytes), nil},
+	)
+	if elapsed := s.Elapsed(); elapsed > 0 {
+		rows = append(rows, row{"Time", fmt.Sprintf("%s (%.0f bytes/sec)",
+			elapsed.Round(time.Millisecond), float64(s.TotalBytes)/elapsed.Seconds()), nil})
+	}
+
+	labelWidth, valueWidth := 0, 0
+	for _, r := range rows {
+		labelWidth = max(labelWidth, len(r.label))
+		if _, err := strconv.Atoi(r.value); err == nil {
+			valueWidth = max(valueWidth, len(r.value))
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(style(headingStyle, "Statistics") + "\n")
+	for _, r := range rows {
+		value := r.value
+		if _, err := strconv.Atoi(value); err == nil {
+			value = fmt.Sprintf("%*s", valueWidth, value)
+		}
+		if r.st != nil {
+			value = style(*r.st, value)
+		}
+		fmt.Fprintf(&b, "  %s  %s\n", style(labelStyle, fmt.Sprintf("%-*s", labelWidth, r.label)), value)
+	}
+
+	langs := sortedLanguages(s.Languages)
+	if len(langs) > 0 {
+		more := 0
+		if top > 0 && len(langs) > top {
+			langs, more = langs[:top], len(langs)-top
+		}
+
+		nameWidth, countWidth := 0, 0
+		for _, l := range langs {
+			nameWidth = max(nameWidth, len(l.Language))
+			countWidth = max(countWidth, len(strconv.Itoa(l.Count)))
+		}
+
+		b.WriteString("\n" + style(headingStyle, "Languages") + "\n")
+		for _, l := range langs {
+			pct := 0.0
+			if s.TotalFiles > 0 {
+				pct = 100 * float64(l.Count) / float64(s.TotalFiles)
+			}
+			fmt.Fprintf(&b, "  %s  %*d  %s\n", style(labelStyle, fmt.Sprintf("%-*s", nameWidth, l.Language)),
+				countWidth, l.Count, fmt.Sprintf("%3.0f%%", pct))
+		}
+		if more > 0 {
+			fmt.Fprintf(&b, "  +%d more\n", more)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
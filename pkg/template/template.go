@@ -0,0 +1,106 @@
+// Package template renders project templates kept as directories of files,
+// such as ~/.goscaffold/templates/api, for new --template.
+package template
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// Suffix marks files that are rendered with text/template; it is dropped
+// from the created file's name. Other files are copied verbatim.
+const Suffix = ".tmpl"
+
+// List returns the names of the template directories under root, sorted.
+// A missing root has none.
+func List(root string) ([]string, error) {
+	entries, err := os.ReadDir(root)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() {
+			names = append(names, e.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// Files returns the slash-separated paths Render would create from dir.
+func Files(dir string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		paths = append(paths, filepath.ToSlash(strings.TrimSuffix(rel, Suffix)))
+		return nil
+	})
+	return paths, err
+}
+
+// Render recreates dir's tree under dst, executing .tmpl files with data
+// and copying the rest. File modes are kept, so scripts stay executable.
+func Render(dir, dst string, data any) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		if d.IsDir() {
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return fmt.Errorf("mkdir %s: %w", target, err)
+			}
+			return nil
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		if strings.HasSuffix(rel, Suffix) {
+			target = strings.TrimSuffix(target, Suffix)
+			t, err := template.New(rel).Parse(string(content))
+			if err != nil {
+				return fmt.Errorf("render %s: %w", rel, err)
+			}
+			var b bytes.Buffer
+			if err := t.Execute(&b, data); err != nil {
+				return fmt.Errorf("render %s: %w", rel, err)
+			}
+			content = b.Bytes()
+		}
+
+		if err := os.WriteFile(target, content, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("write %s: %w", target, err)
+		}
+		return nil
+	})
+}
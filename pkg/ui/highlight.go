@@ -0,0 +1,146 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// maxHighlightLine is the longest line that gets highlighted; longer ones
+// are printed as is.
+const maxHighlightLine = 500
+
+const reset = "\033[0m"
+
+// Theme holds the ANSI sequences used for each kind of token.
+type Theme struct {
+	Keyword string
+	String  string
+	Comment string
+	Number  string
+}
+
+var themes = map[string]Theme{
+	"dark":  {Keyword: "\033[35m", String: "\033[32m", Comment: "\033[90m", Number: "\033[33m"},
+	"light": {Keyword: "\033[34m", String: "\033[31m", Comment: "\033[37m", Number: "\033[36m"},
+	"none":  {},
+}
+
+var (
+	theme = themes["dark"]
+	color = os.Getenv("NO_COLOR") == ""
+)
+
+// SetColor enables or disables colored output. Color is on by default
+// unless NO_COLOR is set.
+func SetColor(enabled bool) {
+	color = enabled
+}
+
+// SetTheme selects the highlighting theme by name: dark, light or none.
+// An empty name keeps the default.
+func SetTheme(name string) error {
+	if name == "" {
+		return nil
+	}
+	t, ok := themes[name]
+	if !ok {
+		return fmt.Errorf("unknown theme %q (want dark, light or none)", name)
+	}
+	theme = t
+	return nil
+}
+
+type syntax struct {
+	keywords    map[string]bool
+	lineComment string
+}
+
+func words(s string) map[string]bool {
+	m := make(map[string]bool)
+	for _, w := range strings.Fields(s) {
+		m[w] = true
+	}
+	return m
+}
+
+var syntaxes = map[string]syntax{
+	".go": {words(`break case chan const continue default defer else fallthrough for func go goto if
+		import interface map package range return select struct switch type var nil true false`), "//"},
+	".py": {words(`and as assert async await break class continue def del elif else except finally for
+		from global if import in is lambda nonlocal not or pass raise return try while with yield None True False`), "#"},
+	".js": {words(`async await break case catch class const continue default delete do else export
+		extends finally for function if import in instanceof let new return switch this throw try typeof
+		var void while yield null undefined true false`), "//"},
+	".json": {words("true false null"), ""},
+	".yaml": {words("true false null"), "#"},
+	".sh":   {words(`if then else elif fi for while do done case esac function in return export local`), "#"},
+}
+
+func init() {
+	syntaxes[".ts"] = syntaxes[".js"]
+	syntaxes[".yml"] = syntaxes[".yaml"]
+}
+
+// highlight colors a single line of code from path according to the
+// extension's syntax. Unknown extensions are returned unchanged.
+func highlight(path, line string) string {
+	syn, ok := syntaxes[strings.ToLower(filepath.Ext(path))]
+	if !ok || !color || theme == (Theme{}) || len(line) > maxHighlightLine {
+		return line
+	}
+
+	var b strings.Builder
+	paint := func(style, s string) {
+		if style == "" {
+			b.WriteString(s)
+			return
+		}
+		b.WriteString(style + s + reset)
+	}
+
+	for i := 0; i < len(line); {
+		c := line[i]
+		switch {
+		case syn.lineComment != "" && strings.HasPrefix(line[i:], syn.lineComment):
+			paint(theme.Comment, line[i:])
+			return b.String()
+		case c == '"' || c == '\'' || c == '`':
+			j := i + 1
+			for j < len(line) && line[j] != c {
+				if line[j] == '\\' && c != '`' {
+					j++
+				}
+				j++
+			}
+			j = min(j+1, len(line))
+			paint(theme.String, line[i:j])
+			i = j
+		case isWordByte(c):
+			j := i
+			for j < len(line) && isWordByte(line[j]) {
+				j++
+			}
+			word := line[i:j]
+			switch {
+			case syn.keywords[word]:
+				paint(theme.Keyword, word)
+			case unicode.IsDigit(rune(c)):
+				paint(theme.Number, word)
+			default:
+				b.WriteString(word)
+			}
+			i = j
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+	return b.String()
+}
+
+func isWordByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+const barWidth = 30
+
+// Progress renders a single-line progress bar. It is safe for concurrent
+// use, and a nil *Progress does nothing, so callers can disable it by not
+// creating one.
+type Progress struct {
+	mu    sync.Mutex
+	w     io.Writer
+	total int
+	done  int
+}
+
+func NewProgress(w io.Writer, total int) *Progress {
+	p := &Progress{w: w, total: total}
+	p.render()
+	return p
+}
+
+// Inc marks one more item as done.
+func (p *Progress) Inc() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.done++
+	p.render()
+}
+
+// Finish ends the progress line.
+func (p *Progress) Finish() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	fmt.Fprintln(p.w)
+}
+
+// render must be called with p.mu held.
+func (p *Progress) render() {
+	filled := barWidth
+	if p.total > 0 {
+		filled = barWidth * p.done / p.total
+	}
+	fmt.Fprintf(p.w, "\r[%s%s] %d/%d", strings.Repeat("#", filled), strings.Repeat(".", barWidth-filled), p.done, p.total)
+}
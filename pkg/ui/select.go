@@ -0,0 +1,107 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"goscaffold/internal/models"
+)
+
+// Select lists files with checkboxes, all initially selected, and lets the
+// user toggle entries until they confirm with an empty line. Entries are
+// toggled by number or range ("2 4-6"); "a" selects all, "n" none and "q"
+// (or EOF) cancels, returning no files.
+func Select(r *bufio.Reader, w io.Writer, files []models.File) ([]models.File, error) {
+	selected := make([]bool, len(files))
+	for i := range selected {
+		selected[i] = true
+	}
+
+	for {
+		renderSelection(w, files, selected)
+		fmt.Fprint(w, "Toggle [numbers/ranges, a=all, n=none, q=quit, Enter=confirm] ")
+
+		line, err := r.ReadString('\n')
+		if err != nil && line == "" {
+			if err == io.EOF {
+				return nil, nil
+			}
+			return nil, err
+		}
+
+		switch input := strings.ToLower(strings.TrimSpace(line)); input {
+		case "":
+			var out []models.File
+			for i, f := range files {
+				if selected[i] {
+					out = append(out, f)
+				}
+			}
+			return out, nil
+		case "q", "quit":
+			return nil, nil
+		case "a", "all":
+			setAll(selected, true)
+		case "n", "none":
+			setAll(selected, false)
+		default:
+			if err := toggle(selected, input); err != nil {
+				fmt.Fprintln(w, err)
+			}
+		}
+	}
+}
+
+func renderSelection(w io.Writer, files []models.File, selected []bool) {
+	fmt.Fprintln(w)
+	var count, bytes int
+	for i, f := range files {
+		box := "[ ]"
+		if selected[i] {
+			box = "[x]"
+			count++
+			bytes += len(f.Code)
+		}
+		fmt.Fprintf(w, "%3d %s %s (%d bytes)\n", i+1, box, f.Path, len(f.Code))
+	}
+	fmt.Fprintf(w, "Selected %d/%d files, %d bytes\n", count, len(files), bytes)
+}
+
+func setAll(selected []bool, v bool) {
+	for i := range selected {
+		selected[i] = v
+	}
+}
+
+// toggle flips the entries named by input, a list of 1-based numbers and
+// ranges. Nothing is changed if any of it is invalid.
+func toggle(selected []bool, input string) error {
+	var idx []int
+	for _, field := range strings.FieldsFunc(input, func(r rune) bool { return r == ' ' || r == ',' }) {
+		lo, hi, isRange := strings.Cut(field, "-")
+		from, err := strconv.Atoi(lo)
+		if err != nil {
+			return fmt.Errorf("invalid entry %q", field)
+		}
+		to := from
+		if isRange {
+			if to, err = strconv.Atoi(hi); err != nil {
+				return fmt.Errorf("invalid range %q", field)
+			}
+		}
+		if from < 1 || to > len(selected) || from > to {
+			return fmt.Errorf("out of range %q (1-%d)", field, len(selected))
+		}
+		for i := from; i <= to; i++ {
+			idx = append(idx, i-1)
+		}
+	}
+
+	for _, i := range idx {
+		selected[i] = !selected[i]
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package ui
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"goscaffold/internal/models"
+)
+
+const previewLines = 10
+
+type Choice int
+
+const (
+	No Choice = iota
+	Yes
+	All
+	Quit
+)
+
+func ShowFilePreview(w io.Writer, f models.File) {
+	lines := strings.Split(strings.TrimSuffix(f.Code, "\n"), "\n")
+
+	fmt.Fprintf(w, "\n── %s (%d bytes, %d lines) ──\n", f.Path, len(f.Code), len(lines))
+	for i, line := range lines {
+		if i == previewLines {
+			fmt.Fprintf(w, "  … %d more lines\n", len(lines)-previewLines)
+			break
+		}
+		fmt.Fprintf(w, "  %s\n", highlight(f.TypedPath(), line))
+	}
+}
+
+// Confirm asks a y/N question. Anything but yes, including end of input,
+// is No.
+func Confirm(r *bufio.Reader, w io.Writer, question string) (bool, error) {
+	fmt.Fprintf(w, "%s [y/N] ", question)
+	line, err := r.ReadString('\n')
+	if err != nil && line == "" {
+		if err == io.EOF {
+			return false, nil
+		}
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(line)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
+
+// Ask prompts with question until it reads a valid answer. An empty answer
+// means No.
+func Ask(r *bufio.Reader, w io.Writer, question string) (Choice, error) {
+	for {
+		fmt.Fprintf(w, "%s [y/N/a/q] ", question)
+
+		line, err := r.ReadString('\n')
+		if err != nil && line == "" {
+			if err == io.EOF {
+				return Quit, nil
+			}
+			return No, err
+		}
+
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "", "n", "no":
+			return No, nil
+		case "y", "yes":
+			return Yes, nil
+		case "a", "all":
+			return All, nil
+		case "q", "quit":
+			return Quit, nil
+		}
+		fmt.Fprintln(w, "Please answer y, n, a (all remaining) or q (quit).")
+	}
+}
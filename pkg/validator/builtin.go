@@ -0,0 +1,70 @@
+package validator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// builtins validate in-process when no command is configured for an
+// extension.
+var builtins = map[string]*Validator{
+	".go":   {Extension: ".go", check: checkGo},
+	".json": {Extension: ".json", check: checkJSON},
+	".yaml": {Extension: ".yaml", check: checkYAML},
+	".yml":  {Extension: ".yml", check: checkYAML},
+}
+
+func checkGo(ctx context.Context, path, code string) error {
+	_, err := parser.ParseFile(token.NewFileSet(), path, code, parser.AllErrors|parser.SkipObjectResolution)
+	return err
+}
+
+func checkJSON(ctx context.Context, path, code string) error {
+	var v interface{}
+	err := json.Unmarshal([]byte(code), &v)
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		line, col := position(code, syntaxErr.Offset)
+		return fmt.Errorf("%s:%d:%d: %w", path, line, col, err)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// checkYAML decodes every document in code; yaml errors already name the
+// offending line.
+func checkYAML(ctx context.Context, path, code string) error {
+	dec := yaml.NewDecoder(strings.NewReader(code))
+	for {
+		var v interface{}
+		err := dec.Decode(&v)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+}
+
+// position converts a byte offset into a 1-based line and column.
+func position(s string, offset int64) (int, int) {
+	if offset > int64(len(s)) {
+		offset = int64(len(s))
+	}
+	before := s[:offset]
+	line := strings.Count(before, "\n") + 1
+	col := len(before) - strings.LastIndex(before, "\n")
+	return line, col
+}
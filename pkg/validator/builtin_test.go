@@ -0,0 +1,40 @@
+package validator
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCheckGo(t *testing.T) {
+	ctx := context.Background()
+
+	valid := "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hi\")\n}\n"
+	if err := checkGo(ctx, "main.go", valid); err != nil {
+		t.Errorf("checkGo(valid) = %v", err)
+	}
+
+	malformed := "package main\n\nfunc main() {\n\tfmt.Println(\"hi\"\n}\n"
+	err := checkGo(ctx, "cmd/main.go", malformed)
+	if err == nil {
+		t.Fatal("checkGo(malformed) succeeded")
+	}
+	// The error points at the file, line and column.
+	if !strings.HasPrefix(err.Error(), "cmd/main.go:4:") {
+		t.Errorf("checkGo error = %q, want it to start with cmd/main.go:4:<col>", err)
+	}
+}
+
+func TestGetForFileBuiltinGo(t *testing.T) {
+	if err := Configure(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := GetForFile("pkg/x.go")
+	if err != nil {
+		t.Fatalf("GetForFile(.go) = %v, want the built-in validator", err)
+	}
+	if err := v.Validate(context.Background(), "pkg/x.go", "package x\nfunc {\n"); err == nil {
+		t.Error("built-in Go validator accepted malformed code")
+	}
+}
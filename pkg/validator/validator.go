@@ -0,0 +1,284 @@
+package validator
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+
+	"goscaffold/pkg/config"
+)
+
+const DefaultTimeout = 30 * time.Second
+
+var (
+	ErrNoValidator = errors.New("no validator configured")
+	ErrTimeout     = errors.New("validator timed out")
+)
+
+type Validator struct {
+	Extension string
+	Command   string
+	Args      []string
+	Timeout   time.Duration
+
+	// Strict validators block the write when validation fails.
+	Strict bool
+
+	// Stdin validators read the code from standard input rather than a
+	// temporary file.
+	Stdin bool
+
+	// Retries is how many times a transient failure is retried, with
+	// exponential backoff, within the single Timeout budget. Failures to
+	// start, kills and RetryExitCodes are transient; other exit codes are
+	// validation failures.
+	Retries        int
+	RetryExitCodes []int
+
+	// check, when set, validates in-process instead of running Command.
+	check func(ctx context.Context, path, code string) error
+
+	// perFile validators take a single path, so ValidateFiles runs them once
+	// per file.
+	perFile bool
+}
+
+var (
+	mu       sync.RWMutex
+	registry = map[string]*Validator{}
+)
+
+// Configure replaces the registered validators with cfgs, keyed by
+// extension (with or without the leading dot).
+func Configure(cfgs []config.Validator) error {
+	validators := make(map[string]*Validator, len(cfgs))
+	for _, c := range cfgs {
+		timeout := DefaultTimeout
+		if c.Timeout != "" {
+			d, err := time.ParseDuration(c.Timeout)
+			if err != nil {
+				return fmt.Errorf("validator %s: invalid timeout %q: %w", c.Extension, c.Timeout, err)
+			}
+			timeout = d
+		}
+
+		ext := normalizeExt(c.Extension)
+		validators[ext] = &Validator{
+			Extension: ext,
+			Command:   c.Command,
+			Args:      c.Args,
+			Timeout:   timeout,
+			Strict:    c.Strict,
+			Stdin:     c.Stdin,
+
+			Retries:        c.Retries,
+			RetryExitCodes: c.RetryExitCodes,
+		}
+	}
+
+	mu.Lock()
+	registry = validators
+	mu.Unlock()
+	return nil
+}
+
+func Get(path string) (*Validator, error) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	if v, ok := registry[normalizeExt(filepath.Ext(path))]; ok {
+		return v, nil
+	}
+	return nil, ErrNoValidator
+}
+
+// GetForFile returns the configured validator for path, falling back to a
+// plugin on PATH and then to a built-in one for extensions that have it.
+func GetForFile(path string) (*Validator, error) {
+	if v, err := Get(path); err == nil {
+		return v, nil
+	}
+	if v, ok := plugin(path); ok {
+		return v, nil
+	}
+	if v, ok := builtins[normalizeExt(filepath.Ext(path))]; ok {
+		return v, nil
+	}
+	return nil, ErrNoValidator
+}
+
+// PluginPrefix names external validators discovered on PATH, like git
+// subcommands: goscaffold-validate-<ext> (e.g. goscaffold-validate-py)
+// validates files with that extension. The plugin is run with the path of a
+// temporary file holding the code as its only argument, once per file in
+// package scope too, and must exit non-zero, ideally printing the problem,
+// when validation fails.
+const PluginPrefix = "goscaffold-validate-"
+
+func plugin(path string) (*Validator, bool) {
+	ext := normalizeExt(filepath.Ext(path))
+	if ext == "." {
+		return nil, false
+	}
+
+	bin, err := exec.LookPath(PluginPrefix + ext[1:])
+	if err != nil {
+		return nil, false
+	}
+	return &Validator{Extension: ext, Command: bin, Timeout: DefaultTimeout, perFile: true}, true
+}
+
+func normalizeExt(ext string) string {
+	return "." + strings.TrimPrefix(strings.ToLower(ext), ".")
+}
+
+// PathPlaceholder in Args is replaced with the file's path in stdin mode.
+const PathPlaceholder = "{path}"
+
+// Validate runs the command against code. By default code is written to a
+// temporary file named like path whose name is appended to Args; in stdin
+// mode code is piped to the command instead. The command is killed when ctx
+// is cancelled or the validator's timeout elapses; the latter is reported as
+// ErrTimeout.
+func (v *Validator) Validate(ctx context.Context, path, code string) error {
+	if v.check != nil {
+		return v.check(ctx, path, code)
+	}
+
+	if v.Stdin {
+		args := make([]string, len(v.Args))
+		for i, a := range v.Args {
+			args[i] = strings.ReplaceAll(a, PathPlaceholder, path)
+		}
+		return v.run(ctx, args, strings.NewReader(code), "", path)
+	}
+
+	tmp, err := os.CreateTemp("", "goscaffold-*-"+filepath.Base(path))
+	if err != nil {
+		return fmt.Errorf("temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(code); err != nil {
+		tmp.Close()
+		return fmt.Errorf("temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("temp file: %w", err)
+	}
+
+	args := append(append([]string{}, v.Args...), tmp.Name())
+	return v.run(ctx, args, nil, tmp.Name(), path)
+}
+
+// ValidateFiles checks files already on disk in a single run, appending all
+// their paths to Args, so tools that need a whole package (go vet, a
+// compiler) see every file at once. In-process, stdin and plugin validators
+// check the files one by one; their errors are joined.
+func (v *Validator) ValidateFiles(ctx context.Context, paths []string) error {
+	if v.check == nil && !v.Stdin && !v.perFile {
+		args := append(append([]string{}, v.Args...), paths...)
+		return v.run(ctx, args, nil, "", "")
+	}
+
+	var errs []error
+	for _, path := range paths {
+		code, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := v.Validate(ctx, path, string(code)); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// retryDelay is the wait before the first retry; it doubles per attempt.
+const retryDelay = 200 * time.Millisecond
+
+// run executes the command, retrying transient failures, and rewrites
+// mentions of tmpName in its output to path.
+func (v *Validator) run(ctx context.Context, args []string, stdin io.Reader, tmpName, path string) error {
+	runCtx, cancel := context.WithTimeout(ctx, v.Timeout)
+	defer cancel()
+
+	// Keep stdin so every attempt can read it from the start.
+	var input []byte
+	if stdin != nil {
+		var err error
+		if input, err = io.ReadAll(stdin); err != nil {
+			return err
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		log.Debug("Running validator", "command", v.Command, "path", path, "attempt", attempt)
+
+		cmd := exec.CommandContext(runCtx, v.Command, args...)
+		if stdin != nil {
+			cmd.Stdin = bytes.NewReader(input)
+		}
+
+		var out bytes.Buffer
+		cmd.Stdout = &out
+		cmd.Stderr = &out
+
+		err := cmd.Run()
+		switch {
+		case err == nil:
+			return nil
+		case ctx.Err() != nil:
+			return ctx.Err()
+		case errors.Is(runCtx.Err(), context.DeadlineExceeded):
+			return fmt.Errorf("%w: %s after %s", ErrTimeout, v.Command, v.Timeout)
+		}
+
+		if attempt <= v.Retries && v.transient(err) {
+			delay := retryDelay << (attempt - 1)
+			log.Debug("Validator failed, retrying", "command", v.Command, "path", path, "in", delay, "error", err)
+			// If the budget runs out meanwhile, the next attempt fails at
+			// once and is reported as a timeout or cancellation above.
+			select {
+			case <-runCtx.Done():
+			case <-time.After(delay):
+			}
+			continue
+		}
+
+		msg := out.String()
+		if tmpName != "" {
+			msg = strings.ReplaceAll(msg, tmpName, path)
+		}
+		return fmt.Errorf("%s: %w: %s", v.Command, err, strings.TrimSpace(msg))
+	}
+}
+
+// transient reports whether err from running the command is worth
+// retrying rather than a validation failure.
+func (v *Validator) transient(err error) bool {
+	if errors.Is(err, exec.ErrNotFound) {
+		return false
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return true // failed to start, e.g. "text file busy"
+	}
+	code := exitErr.ExitCode()
+	return code == -1 || slices.Contains(v.RetryExitCodes, code)
+}
@@ -0,0 +1,124 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestHelperProcess is the fake validator command: run as a child with
+// GS_TEST_HELPER set, it echoes its stdin and arguments and fails when the
+// input contains "invalid".
+func TestHelperProcess(t *testing.T) {
+	if os.Getenv("GS_TEST_HELPER") != "1" {
+		return
+	}
+	in, _ := io.ReadAll(os.Stdin)
+	args := os.Args
+	for i, a := range args {
+		if a == "--" {
+			args = args[i+1:]
+			break
+		}
+	}
+	fmt.Printf("stdin=%q args=%q", in, args)
+	if strings.Contains(string(in), "invalid") {
+		os.Exit(1)
+	}
+	os.Exit(0)
+}
+
+func helperValidator(t *testing.T) *Validator {
+	t.Helper()
+	t.Setenv("GS_TEST_HELPER", "1")
+	return &Validator{
+		Extension: ".txt",
+		Command:   os.Args[0],
+		Args:      []string{"-test.run=^TestHelperProcess$", "--", "--stdin-filepath", PathPlaceholder},
+		Timeout:   10 * time.Second,
+		Stdin:     true,
+	}
+}
+
+func TestValidateStdin(t *testing.T) {
+	v := helperValidator(t)
+	ctx := context.Background()
+
+	if err := v.Validate(ctx, "dir/ok.txt", "all good\n"); err != nil {
+		t.Errorf("Validate(valid) = %v", err)
+	}
+
+	err := v.Validate(ctx, "dir/bad.txt", "invalid input\n")
+	if err == nil {
+		t.Fatal("Validate(invalid) succeeded")
+	}
+	// The code arrives on stdin and the path only as the filename hint.
+	for _, want := range []string{`stdin="invalid input\n"`, `args=["--stdin-filepath" "dir/bad.txt"]`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not contain %s", err, want)
+		}
+	}
+}
+
+func TestValidateFilesStdin(t *testing.T) {
+	v := helperValidator(t)
+	dir := t.TempDir()
+	good, bad := filepath.Join(dir, "good.txt"), filepath.Join(dir, "bad.txt")
+	if err := os.WriteFile(good, []byte("fine\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(bad, []byte("invalid\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	err := v.ValidateFiles(context.Background(), []string{good, bad})
+	if err == nil || !strings.Contains(err.Error(), "bad.txt") || strings.Contains(err.Error(), "good.txt") {
+		t.Errorf("ValidateFiles = %v, want only bad.txt to fail", err)
+	}
+}
+
+func TestValidateFilesPluginOnePathEach(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugin is a shell script")
+	}
+	bin := t.TempDir()
+	script := "#!/bin/sh\n" +
+		"[ $# -eq 1 ] || { echo \"got $# paths\"; exit 2; }\n" +
+		"if grep -q invalid \"$1\"; then echo \"$1: invalid\"; exit 1; fi\n"
+	if err := os.WriteFile(filepath.Join(bin, PluginPrefix+"gsx"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	dir := t.TempDir()
+	var paths []string
+	for _, name := range []string{"a.gsx", "b.gsx", "bad.gsx"} {
+		path := filepath.Join(dir, name)
+		code := "fine\n"
+		if name == "bad.gsx" {
+			code = "invalid\n"
+		}
+		if err := os.WriteFile(path, []byte(code), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+
+	v, err := GetForFile(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := v.ValidateFiles(context.Background(), paths[:2]); err != nil {
+		t.Errorf("ValidateFiles(valid) = %v", err)
+	}
+	err = v.ValidateFiles(context.Background(), paths)
+	if err == nil || !strings.Contains(err.Error(), "bad.gsx: invalid") || strings.Contains(err.Error(), "paths") {
+		t.Errorf("ValidateFiles = %v, want only bad.gsx to fail", err)
+	}
+}
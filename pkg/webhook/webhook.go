@@ -0,0 +1,55 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const Timeout = 10 * time.Second
+
+// Payload is the JSON body posted after an import. Text is a one-line
+// summary, which is what Slack-style incoming webhooks display.
+type Payload struct {
+	Text  string          `json:"text"`
+	OK    bool            `json:"ok"`
+	Error string          `json:"error,omitempty"`
+	Stats json.RawMessage `json:"stats,omitempty"`
+}
+
+// Send posts p to url as JSON, giving up after Timeout. Any 2xx status is
+// success.
+func Send(ctx context.Context, url string, p Payload) error {
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	body, err := json.Marshal(p)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid webhook url %q: %w", url, err)
+	}
+	if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+		return fmt.Errorf("invalid webhook url %q: scheme must be http or https", url)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("post %s: %w", url, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("post %s: %s", url, resp.Status)
+	}
+	return nil
+}